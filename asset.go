@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"github.com/decred/dcrd/dcrec/secp256k1/v3"
+	"math/big"
+)
+
+//机密资产：每种资产有自己的值生成元H_asset，输出使用盲化的资产标签A = H_asset + r*G
+//金额承诺为v*A + gamma*G，即Commit(A,G,v,gamma)，范围证明以A为值生成元、G为盲因子生成元
+//资产满射证明说明输出标签是某个输入标签的重新盲化：A_out - A_in_k = (r_out - r_k)*G
+//用AOS环签名证明知道某个A_out - A_in_i对G的离散对数，而不泄露是哪一个
+
+type SurjectionProof struct {
+	e0 *big.Int
+	s  []*big.Int
+}
+
+//由资产标识导出值生成元，对x坐标做try-and-increment，没有人知道它与G,H的离散对数关系
+func AssetGenerator(asset []byte) Point {
+	var t Transcript
+	t.New("asset generator")
+	t.AppendBytes("asset", asset)
+	for {
+		x := t.ChallengeScalar("x")
+		buf := make([]byte, pointSize)
+		buf[0] = 0x02
+		x.FillBytes(buf[1:])
+		if pub, err := secp256k1.ParsePubKey(buf); err == nil {
+			return Point{x: pub.X(), y: pub.Y()}
+		}
+	}
+}
+
+//盲化的资产标签A = H_asset + r*G
+func BlindAsset(G Point, asset []byte, r *big.Int) Point {
+	return MultiCommitCT(AssetGenerator(asset), CommitSingleCT(G, modN(r).Bytes()))
+}
+
+//对资产标签为tag的金额v的承诺：v*tag + gamma*G
+func CommitAsset(tag Point, G Point, v *big.Int, gamma *big.Int) Point {
+	return CommitCT(tag, G, modN(v).Bytes(), modN(gamma).Bytes())
+}
+
+//以输出的资产标签为值生成元生成范围证明
+func ProveAssetRange(mode RangeProofMode, tag Point, G Point, GVector []Point, HVector []Point, v *big.Int, gamma *big.Int, n int64, transcript *Transcript) (RangeProof, error) {
+	if !IsOnCurve(tag) {
+		return RangeProof{}, fmt.Errorf("%w: 资产标签", ErrInvalidPoint)
+	}
+	return ProveRange(mode, tag, G, GVector, HVector, v, gamma, n, transcript)
+}
+
+//验证以资产标签为值生成元的范围证明，n是要求的位数
+func VerifyAssetRange(tag Point, G Point, GVector []Point, HVector []Point, V Point, n int64, proof RangeProof, transcript *Transcript) error {
+	if !IsOnCurve(tag) {
+		return fmt.Errorf("%w: 资产标签", ErrInvalidPoint)
+	}
+	return VerifyRange(tag, G, GVector, HVector, V, n, proof, transcript)
+}
+
+//环上的公钥P_i = A_out - A_in_i
+func surjectionKeys(inputs []Point, output Point) []Point {
+	var keys []Point
+	minusOne := negBig(big.NewInt(1)).Bytes()
+	for _, input := range inputs {
+		keys = append(keys, MultiCommit(output, CommitSingle(input, minusOne)))
+	}
+	return keys
+}
+
+func appendSurjectionStatement(transcript *Transcript, inputs []Point, output Point) {
+	transcript.AppendBytes("surjection", []byte{1})
+	transcript.AppendInt("inputs", int64(len(inputs)))
+	for _, input := range inputs {
+		transcript.AppendPoint("input", input)
+	}
+	transcript.AppendPoint("output", output)
+}
+
+//环上第i+1个挑战e = H(statement, i, R_i)，每次从同一个transcript状态出发
+func ringChallenge(base Transcript, i int, R Point) *big.Int {
+	base.AppendInt("i", int64(i))
+	base.AppendPoint("R", R)
+	return base.ChallengeScalar("e")
+}
+
+//生成满射证明：输出标签output = inputs[index]重新盲化，rIn,rOut分别是两者的盲因子
+func ProveSurjection(G Point, inputs []Point, output Point, index int, rIn *big.Int, rOut *big.Int, transcript *Transcript) (SurjectionProof, error) {
+	if len(inputs) == 0 {
+		return SurjectionProof{}, ErrEmptySet
+	}
+	if index < 0 || index >= len(inputs) {
+		return SurjectionProof{}, ErrNotInSet
+	}
+	keys := surjectionKeys(inputs, output)
+	x := subInP(modN(rOut), modN(rIn))
+	if !IsEqual(keys[index], CommitSingleCT(G, x.Bytes())) {
+		return SurjectionProof{}, ErrNotInSet
+	}
+	appendSurjectionStatement(transcript, inputs, output)
+	base := *transcript
+
+	//R_i = s_i*G + e_i*P_i，e_(i+1) = H(R_i)；在index处用s = k - e*x闭合环
+	N := len(inputs)
+	e := make([]*big.Int, N)
+	s := make([]*big.Int, N)
+	k := GenerateRandomScalar()
+	e[(index+1)%N] = ringChallenge(base, index, CommitSingleCT(G, k.Bytes()))
+	for i := (index + 1) % N; i != index; i = (i + 1) % N {
+		s[i] = GenerateRandomScalar()
+		e[(i+1)%N] = ringChallenge(base, i, Commit(G, keys[i], s[i].Bytes(), e[i].Bytes()))
+	}
+	s[index] = subInP(k, mulInP(e[index], x))
+	*transcript = base
+	transcript.AppendScalar("e0", e[0])
+	return SurjectionProof{e0: e[0], s: s}, nil
+}
+
+//验证输出标签是某个输入标签的重新盲化
+func VerifySurjection(G Point, inputs []Point, output Point, proof SurjectionProof, transcript *Transcript) error {
+	if len(inputs) == 0 {
+		return ErrEmptySet
+	}
+	if len(proof.s) != len(inputs) {
+		return fmt.Errorf("%w: s的个数与输入个数不符", ErrMalformedProof)
+	}
+	for _, value := range append([]*big.Int{proof.e0}, proof.s...) {
+		if value == nil || value.Sign() < 0 || value.Cmp(curve.N) >= 0 {
+			return fmt.Errorf("%w: 标量超出范围", ErrMalformedProof)
+		}
+	}
+	for _, p := range append([]Point{output}, inputs...) {
+		if !IsOnCurve(p) {
+			return fmt.Errorf("%w: 资产标签", ErrInvalidPoint)
+		}
+	}
+	keys := surjectionKeys(inputs, output)
+	appendSurjectionStatement(transcript, inputs, output)
+	base := *transcript
+
+	e := proof.e0
+	for i := range keys {
+		e = ringChallenge(base, i, Commit(G, keys[i], proof.s[i].Bytes(), e.Bytes()))
+	}
+	if e.Cmp(proof.e0) != 0 {
+		return ErrSurjectionFailed
+	}
+	transcript.AppendScalar("e0", proof.e0)
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestAssetRange(t *testing.T) {
+	G, _, GVector, HVector := testGenerators(32)
+	r := GenerateRandomScalar()
+	tag := BlindAsset(G, []byte("gold"), r)
+	v, gamma := big.NewInt(1000), GenerateRandomScalar()
+	V := CommitAsset(tag, G, v, gamma)
+	tests := []struct {
+		name    string
+		tag     Point
+		V       Point
+		n       int64
+		wantErr error
+		reject  bool
+	}{
+		{"诚实的证明", tag, V, 16, nil, false},
+		{"要求的范围不同", tag, V, 32, ErrMalformedProof, false},
+		{"n = 256", tag, V, 256, ErrInvalidRangeWidth, false},
+		{"换了资产标签", BlindAsset(G, []byte("silver"), r), V, 16, nil, true},
+		{"标签不在曲线上", Point{x: big.NewInt(1), y: big.NewInt(1)}, V, 16, ErrInvalidPoint, false},
+	}
+	for _, mode := range rangeProofModes {
+		proof, err := ProveAssetRange(mode.mode, tag, G, GVector, HVector, v, gamma, 16, newTranscript("asset test"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, test := range tests {
+			t.Run(mode.name+"/"+test.name, func(t *testing.T) {
+				err := VerifyAssetRange(test.tag, G, GVector, HVector, test.V, test.n, proof, newTranscript("asset test"))
+				switch {
+				case test.reject:
+					if err == nil {
+						t.Fatal("错误的证明通过了验证")
+					}
+				case !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil):
+					t.Fatalf("err = %v，应为%v", err, test.wantErr)
+				}
+			})
+		}
+	}
+}
+
+func TestSurjection(t *testing.T) {
+	G := GeneratePoint()
+	assets := [][]byte{[]byte("gold"), []byte("silver"), []byte("copper")}
+	var inputs []Point
+	var rIn []*big.Int
+	for _, asset := range assets {
+		r := GenerateRandomScalar()
+		rIn = append(rIn, r)
+		inputs = append(inputs, BlindAsset(G, asset, r))
+	}
+	rOut := GenerateRandomScalar()
+	output := BlindAsset(G, assets[1], rOut)
+	tests := []struct {
+		name    string
+		inputs  []Point
+		tamper  func(proof *SurjectionProof)
+		wantErr error
+	}{
+		{"诚实的证明", inputs, nil, nil},
+		{"篡改s", inputs, func(proof *SurjectionProof) { proof.s[0] = addInP(proof.s[0], big.NewInt(1)) }, ErrSurjectionFailed},
+		{"篡改e0", inputs, func(proof *SurjectionProof) { proof.e0 = addInP(proof.e0, big.NewInt(1)) }, ErrSurjectionFailed},
+		{"s的个数不对", inputs, func(proof *SurjectionProof) { proof.s = proof.s[:2] }, ErrMalformedProof},
+		{"标量超出Zp", inputs, func(proof *SurjectionProof) { proof.s[2] = big.NewInt(0).Set(curve.N) }, ErrMalformedProof},
+		{"输入被替换", []Point{inputs[0], inputs[2], inputs[1]}, nil, ErrSurjectionFailed},
+		{"没有输入", nil, nil, ErrEmptySet},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			proof, err := ProveSurjection(G, inputs, output, 1, rIn[1], rOut, newTranscript("surjection test"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.tamper != nil {
+				test.tamper(&proof)
+			}
+			err = VerifySurjection(G, test.inputs, output, proof, newTranscript("surjection test"))
+			if !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil) {
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+		})
+	}
+
+	//输出的资产不在输入中时无法生成证明
+	other := BlindAsset(G, []byte("platinum"), rOut)
+	if _, err := ProveSurjection(G, inputs, other, 1, rIn[1], rOut, newTranscript("surjection test")); !errors.Is(err, ErrNotInSet) {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := ProveSurjection(G, inputs, output, 3, rIn[1], rOut, newTranscript("surjection test")); !errors.Is(err, ErrNotInSet) {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+)
+
+//密封投标拍卖：投标人提交Commit(bid,r)、bid在[0,2^n)中的范围证明，以及把打开加密给拍卖人的信封（即ConfidentialAmount）
+//拍卖人解密所有出价，选出最高价（相同出价时先投标者胜出），公开中标价及其打开证明
+//并对每个落选者给出比较证明：早于中标者的出价 < 中标价，晚于中标者的出价 <= 中标价
+//落选者的出价始终只以承诺的形式出现
+
+type AuctionParams struct {
+	Mode             RangeProofMode
+	G, H             Point
+	GVector, HVector []Point
+	N                int64
+	Auctioneer       Point //拍卖人的公钥
+}
+
+type SealedBid struct {
+	Bidder string
+	Amount ConfidentialAmount
+}
+
+type Auction struct {
+	params  AuctionParams
+	bids    []SealedBid
+	bidders map[string]bool
+}
+
+type AuctionResult struct {
+	Winner      int
+	Price       *big.Int
+	PriceProof  OpeningProof
+	Comparisons []ComparisonProof //第i个是出价i与中标价的比较，i == Winner时为空
+}
+
+//投标的transcript覆盖投标人，出价不能被别人冒用
+func bidTranscript(bidder string) *Transcript {
+	var t Transcript
+	t.New("sealed bid")
+	t.AppendBytes("bidder", []byte(bidder))
+	return &t
+}
+
+func auctionTranscript(winner int, i int) *Transcript {
+	var t Transcript
+	t.New("auction result")
+	t.AppendInt("winner", int64(winner))
+	t.AppendInt("bid", int64(i))
+	return &t
+}
+
+//投标人生成密封出价
+func (params AuctionParams) SubmitBid(bidder string, bid *big.Int) (SealedBid, error) {
+	amount, err := NewConfidentialAmount(params.Mode, params.G, params.H, params.GVector, params.HVector, bid, GenerateRandomScalar(), params.N, params.Auctioneer, bidTranscript(bidder))
+	if err != nil {
+		return SealedBid{}, err
+	}
+	return SealedBid{Bidder: bidder, Amount: amount}, nil
+}
+
+func (auction *Auction) New(params AuctionParams) {
+	auction.params = params
+	auction.bids = nil
+	auction.bidders = make(map[string]bool)
+}
+
+//验证出价的范围证明并接受投标，每个投标人只能投一次
+func (auction *Auction) AddBid(bid SealedBid) error {
+	if auction.bidders[bid.Bidder] {
+		return fmt.Errorf("%w: %q", ErrDuplicateBidder, bid.Bidder)
+	}
+	params := auction.params
+	if err := bid.Amount.Verify(params.G, params.H, params.GVector, params.HVector, params.N, bidTranscript(bid.Bidder)); err != nil {
+		return err
+	}
+	auction.bidders[bid.Bidder] = true
+	auction.bids = append(auction.bids, bid)
+	return nil
+}
+
+//公开的出价列表
+func (auction Auction) Bids() []SealedBid {
+	return append([]SealedBid{}, auction.bids...)
+}
+
+//拍卖人解密出价，确定中标者并生成证明
+func (auction Auction) Close(priv *big.Int) (AuctionResult, error) {
+	if len(auction.bids) == 0 {
+		return AuctionResult{}, ErrEmptySet
+	}
+	params := auction.params
+	values := make([]*big.Int, len(auction.bids))
+	gammas := make([]*big.Int, len(auction.bids))
+	winner := 0
+	for i, bid := range auction.bids {
+		v, gamma, err := bid.Amount.Open(params.G, params.H, priv)
+		if err != nil {
+			return AuctionResult{}, fmt.Errorf("投标人%q: %w", bid.Bidder, err)
+		}
+		values[i], gammas[i] = v, gamma
+		if v.Cmp(values[winner]) > 0 {
+			winner = i
+		}
+	}
+
+	result := AuctionResult{Winner: winner, Price: values[winner]}
+	result.PriceProof = ProveOpening(params.G, params.H, values[winner], gammas[winner], auctionTranscript(winner, winner))
+	for i := range auction.bids {
+		var proof ComparisonProof
+		var err error
+		switch {
+		case i < winner:
+			proof, err = ProveLessThan(params.Mode, params.G, params.H, params.GVector, params.HVector, values[i], gammas[i], values[winner], gammas[winner], params.N, auctionTranscript(winner, i))
+		case i > winner:
+			proof, err = ProveLessOrEqual(params.Mode, params.G, params.H, params.GVector, params.HVector, values[i], gammas[i], values[winner], gammas[winner], params.N, auctionTranscript(winner, i))
+		}
+		if err != nil {
+			return AuctionResult{}, err
+		}
+		result.Comparisons = append(result.Comparisons, proof)
+	}
+	return result, nil
+}
+
+//任何人都可以验证中标价和中标者的出价不低于其他所有出价，比较证明的范围必须是约定的n
+func (auction Auction) VerifyResult(result AuctionResult) error {
+	params := auction.params
+	if result.Winner < 0 || result.Winner >= len(auction.bids) || len(result.Comparisons) != len(auction.bids) {
+		return fmt.Errorf("%w: 拍卖结果与投标数不符", ErrMalformedProof)
+	}
+	Vw := auction.bids[result.Winner].Amount.V
+	if err := VerifyOpening(params.G, params.H, Vw, result.Price, result.PriceProof, auctionTranscript(result.Winner, result.Winner)); err != nil {
+		return err
+	}
+	for i, bid := range auction.bids {
+		var err error
+		switch {
+		case i < result.Winner:
+			err = VerifyLessThan(params.G, params.H, params.GVector, params.HVector, bid.Amount.V, Vw, params.N, result.Comparisons[i], auctionTranscript(result.Winner, i))
+		case i > result.Winner:
+			err = VerifyLessOrEqual(params.G, params.H, params.GVector, params.HVector, bid.Amount.V, Vw, params.N, result.Comparisons[i], auctionTranscript(result.Winner, i))
+		}
+		if err != nil {
+			return fmt.Errorf("投标人%q: %w", bid.Bidder, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestAuction(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(32)
+	priv, auctioneer := GenerateKeyPair()
+	params := AuctionParams{Mode: ModeBulletproofs, G: G, H: H, GVector: GVector, HVector: HVector, N: 8, Auctioneer: auctioneer}
+	var auction Auction
+	auction.New(params)
+	if _, err := auction.Close(priv); !errors.Is(err, ErrEmptySet) {
+		t.Fatalf("没有投标: err = %v", err)
+	}
+	bids := []struct {
+		bidder string
+		value  int64
+	}{
+		{"alice", 50},
+		{"bob", 120},
+		{"carol", 120},
+		{"dave", 30},
+	}
+	for _, bid := range bids {
+		sealed, err := params.SubmitBid(bid.bidder, big.NewInt(bid.value))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := auction.AddBid(sealed); err != nil {
+			t.Fatalf("%s: %v", bid.bidder, err)
+		}
+	}
+	result, err := auction.Close(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Winner != 1 || result.Price.Int64() != 120 {
+		t.Fatalf("中标者%d，中标价%v", result.Winner, result.Price)
+	}
+
+	//carol的出价与中标价相同，用16位的比较证明冒充约定的8位
+	carol, carolGamma, err := auction.bids[2].Amount.Open(G, H, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, bobGamma, err := auction.bids[1].Amount.Open(G, H, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wide, err := ProveLessOrEqual(params.Mode, G, H, GVector, HVector, carol, carolGamma, result.Price, bobGamma, 16, auctionTranscript(1, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		tamper  func(result *AuctionResult)
+		wantErr error
+		reject  bool
+	}{
+		{"诚实的结果", nil, nil, false},
+		{"比较证明的范围不是约定的n", func(result *AuctionResult) { result.Comparisons[2] = wide }, ErrMalformedProof, false},
+		{"篡改中标价", func(result *AuctionResult) { result.Price = big.NewInt(121) }, ErrOpeningFailed, false},
+		{"声称别人中标", func(result *AuctionResult) { result.Winner = 2 }, nil, true},
+		{"交换比较证明", func(result *AuctionResult) {
+			result.Comparisons[0], result.Comparisons[3] = result.Comparisons[3], result.Comparisons[0]
+		}, nil, true},
+		{"缺少比较证明", func(result *AuctionResult) { result.Comparisons = result.Comparisons[:3] }, ErrMalformedProof, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tampered := result
+			tampered.Comparisons = append([]ComparisonProof{}, result.Comparisons...)
+			if test.tamper != nil {
+				test.tamper(&tampered)
+			}
+			err := auction.VerifyResult(tampered)
+			switch {
+			case test.reject:
+				if err == nil {
+					t.Fatal("错误的结果通过了验证")
+				}
+			case !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil):
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuctionBid(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(16)
+	_, auctioneer := GenerateKeyPair()
+	params := AuctionParams{Mode: ModeBulletproofs, G: G, H: H, GVector: GVector, HVector: HVector, N: 8, Auctioneer: auctioneer}
+	var auction Auction
+	auction.New(params)
+
+	bid, err := params.SubmitBid("alice", big.NewInt(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wideParams := params
+	wideParams.N = 16
+	wide, err := wideParams.SubmitBid("bob", big.NewInt(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		bid     SealedBid
+		wantErr error
+		reject  bool
+	}{
+		{"冒用别人的出价", SealedBid{Bidder: "mallory", Amount: bid.Amount}, nil, true},
+		{"出价的范围不是约定的n", wide, ErrMalformedProof, false},
+		{"诚实的出价", bid, nil, false},
+		{"重复投标", bid, ErrDuplicateBidder, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := auction.AddBid(test.bid)
+			switch {
+			case test.reject:
+				if !IsInvalidProof(err) {
+					t.Fatalf("err = %v，应为证明无效", err)
+				}
+			case !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil):
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+		})
+	}
+	if _, err := params.SubmitBid("carol", big.NewInt(256)); !errors.Is(err, ErrValueOutOfRange) {
+		t.Fatalf("err = %v", err)
+	}
+}
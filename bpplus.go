@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+)
+
+//Bulletproofs+范围证明
+//只有一个承诺A，不需要T1,T2；把v在[0,2^n)中的关系直接化为加权内积关系，再用加权内积论证(WIP)证明
+//证明包含2log(n)+3个点和3个数
+
+type BPPlusProof struct {
+	A      Point
+	L, R   []Point
+	A1, B  Point
+	r1, s1 *big.Int
+	delta1 *big.Int
+}
+
+//计算加权内积Σa_i*b_i*y^(i+1)
+func weightedInnerProduct(a []*big.Int, b []*big.Int, y *big.Int) *big.Int {
+	sum := big.NewInt(0)
+	power := big.NewInt(1)
+	for key := range a {
+		power = mulInP(power, y)
+		sum = addInP(sum, mulInP(mulInP(a[key], b[key]), power))
+	}
+	return sum
+}
+
+//判断n是否是2的幂
+func isPowerOfTwo(n int64) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+//根据y,z计算范围证明化为WIP时用到的公开参数
+//dy_i = z^2*2^i*y^(n-i)，zeta = (z-z^2)*Σy^i - z*y^(n+1)*Σz^2*2^i，zy = z^2*y^(n+1)
+func bpPlusParams(y *big.Int, z *big.Int, n int64) (dy []*big.Int, zeta *big.Int, zy *big.Int) {
+	yn := GenerateYBig(y, n+2)
+	z2 := mulInP(z, z)
+	twoN := GenerateY(2, n)
+
+	sumY := big.NewInt(0)
+	sumD := big.NewInt(0)
+	for i := int64(0); i < n; i++ {
+		d := mulInP(z2, twoN[i])
+		dy = append(dy, mulInP(d, yn[n-i]))
+		sumY = addInP(sumY, yn[i+1])
+		sumD = addInP(sumD, d)
+	}
+	zeta = subInP(mulInP(subInP(z, z2), sumY), mulInP(mulInP(z, yn[n+1]), sumD))
+	zy = mulInP(z2, yn[n+1])
+	return dy, zeta, zy
+}
+
+func proveBPPlus(G Point, H Point, GVector []Point, HVector []Point, v *big.Int, gamma *big.Int, n int64, transcript *Transcript) (BPPlusProof, error) {
+	var proof BPPlusProof
+	aL, err := GenerateA_LCT(v, n)
+	if err != nil {
+		return proof, err
+	}
+	aR := GenerateA_R(aL)
+
+	alpha := GenerateRandomScalar()
+	proof.A = MultiCommitCT(CommitVectorsCT(GVector, HVector, aL, aR), CommitSingleCT(H, alpha.Bytes()))
+	transcript.AppendPoint("A", proof.A)
+	y := transcript.ChallengeScalar("y")
+	z := transcript.ChallengeScalar("z")
+
+	//â_L = aL - z, â_R = aR + d∘y← + z, α̂ = α + z^2*y^(n+1)*gamma
+	dy, _, zy := bpPlusParams(y, z, n)
+	zVector := CalVectorTimesBig(GenerateYBig(big.NewInt(1), n), z)
+	a := CalVectorSub(aL, zVector)
+	b := CalVectorAdd(CalVectorAdd(aR, dy), zVector)
+	alphaHat := addInP(alpha, mulInP(zy, gamma))
+
+	proveWIP(G, H, GVector, HVector, a, b, alphaHat, y, transcript, &proof)
+	return proof, nil
+}
+
+//加权内积论证：证明知道a,b,alpha，使P = <a,g> + <b,h> + <a,b>_y*G + alpha*H
+func proveWIP(G Point, H Point, g []Point, h []Point, a []*big.Int, b []*big.Int, alpha *big.Int, y *big.Int, transcript *Transcript, proof *BPPlusProof) {
+	yInv := inverseBig(y)
+	for len(a) > 1 {
+		n2 := len(a) / 2
+		a1, a2 := a[:n2], a[n2:]
+		b1, b2 := b[:n2], b[n2:]
+		g1, g2 := g[:n2], g[n2:]
+		h1, h2 := h[:n2], h[n2:]
+		yn2 := GenerateYBig(y, int64(n2)+1)[n2]
+		yInvn2 := GenerateYBig(yInv, int64(n2)+1)[n2]
+
+		//L = g2^(y^-n'*a1) + h1^b2 + cL*G + dL*H，R = g1^(y^n'*a2) + h2^b1 + cR*G + dR*H
+		cL := weightedInnerProduct(a1, b2, y)
+		cR := mulInP(yn2, weightedInnerProduct(a2, b1, y))
+		dL := GenerateRandomScalar()
+		dR := GenerateRandomScalar()
+		L := MultiCommitCT(CommitVectorsCT(g2, h1, CalVectorTimesBig(a1, yInvn2), b2), CommitCT(G, H, cL.Bytes(), dL.Bytes()))
+		R := MultiCommitCT(CommitVectorsCT(g1, h2, CalVectorTimesBig(a2, yn2), b1), CommitCT(G, H, cR.Bytes(), dR.Bytes()))
+		proof.L = append(proof.L, L)
+		proof.R = append(proof.R, R)
+		transcript.AppendPoint("L", L)
+		transcript.AppendPoint("R", R)
+
+		e := transcript.ChallengeScalar("e")
+		eInv := inverseBig(e)
+		e2 := mulInP(e, e)
+		eInv2 := mulInP(eInv, eInv)
+		g, h = foldGenerators(g1, g2, h1, h2, e, eInv, yInvn2)
+		a = CalVectorAdd(CalVectorTimesBig(a1, e), CalVectorTimesBig(a2, mulInP(yn2, eInv)))
+		b = CalVectorAdd(CalVectorTimesBig(b1, eInv), CalVectorTimesBig(b2, e))
+		alpha = addInP(addInP(alpha, mulInP(dL, e2)), mulInP(dR, eInv2))
+	}
+
+	//最后一轮：A' = r*g + s*h + y(r*b + s*a)*G + delta*H，B = r*y*s*G + eta*H
+	r := GenerateRandomScalar()
+	s := GenerateRandomScalar()
+	delta := GenerateRandomScalar()
+	eta := GenerateRandomScalar()
+	cross := mulInP(y, addInP(mulInP(r, b[0]), mulInP(s, a[0])))
+	proof.A1 = MultiCommitCT(CommitCT(g[0], h[0], r.Bytes(), s.Bytes()), CommitCT(G, H, cross.Bytes(), delta.Bytes()))
+	proof.B = CommitCT(G, H, mulInP(mulInP(r, y), s).Bytes(), eta.Bytes())
+	transcript.AppendPoint("A1", proof.A1)
+	transcript.AppendPoint("B", proof.B)
+
+	e := transcript.ChallengeScalar("e")
+	proof.r1 = addInP(r, mulInP(a[0], e))
+	proof.s1 = addInP(s, mulInP(b[0], e))
+	proof.delta1 = addInP(addInP(eta, mulInP(delta, e)), mulInP(alpha, mulInP(e, e)))
+}
+
+//折叠生成元：g' = g1^(e^-1) ∘ g2^(e*y^-n')，h' = h1^e ∘ h2^(e^-1)
+func foldGenerators(g1 []Point, g2 []Point, h1 []Point, h2 []Point, e *big.Int, eInv *big.Int, yInvn2 *big.Int) ([]Point, []Point) {
+	var g, h []Point
+	eY := mulInP(e, yInvn2)
+	for i := range g1 {
+		g = append(g, Commit(g1[i], g2[i], eInv.Bytes(), eY.Bytes()))
+		h = append(h, Commit(h1[i], h2[i], e.Bytes(), eInv.Bytes()))
+	}
+	return g, h
+}
+
+//检查证明的格式，rounds是WIP的轮数log(n)
+func (proof BPPlusProof) check(rounds int) error {
+	if len(proof.L) != rounds || len(proof.R) != rounds {
+		return fmt.Errorf("%w: L,R的个数不等于log(n)", ErrMalformedProof)
+	}
+	for _, p := range append(append([]Point{proof.A, proof.A1, proof.B}, proof.L...), proof.R...) {
+		if !IsOnCurve(p) {
+			return fmt.Errorf("%w: %v", ErrMalformedProof, ErrInvalidPoint)
+		}
+	}
+	for _, value := range []*big.Int{proof.r1, proof.s1, proof.delta1} {
+		if value == nil || value.Sign() < 0 || value.Cmp(curve.N) >= 0 {
+			return fmt.Errorf("%w: 标量超出范围", ErrMalformedProof)
+		}
+	}
+	return nil
+}
+
+func verifyBPPlus(G Point, H Point, GVector []Point, HVector []Point, V Point, n int64, proof BPPlusProof, transcript *Transcript) error {
+	rounds := 0
+	for 1<<uint(rounds) < n {
+		rounds++
+	}
+	if err := proof.check(rounds); err != nil {
+		return err
+	}
+	transcript.AppendPoint("A", proof.A)
+	y := transcript.ChallengeScalar("y")
+	z := transcript.ChallengeScalar("z")
+
+	//各轮的挑战e_j以及y^-n'
+	var es, eInvs, yInvs []*big.Int
+	yInv := inverseBig(y)
+	for i, n2 := 0, n/2; i < rounds; i, n2 = i+1, n2/2 {
+		transcript.AppendPoint("L", proof.L[i])
+		transcript.AppendPoint("R", proof.R[i])
+		e := transcript.ChallengeScalar("e")
+		es = append(es, e)
+		eInvs = append(eInvs, inverseBig(e))
+		yInvs = append(yInvs, GenerateYBig(yInv, n2+1)[n2])
+	}
+	transcript.AppendPoint("A1", proof.A1)
+	transcript.AppendPoint("B", proof.B)
+	e := transcript.ChallengeScalar("e")
+	e2 := mulInP(e, e)
+
+	//不逐轮折叠生成元，而是直接算出最终g,h在GVector,HVector上的系数sg,sh
+	//第j轮中下标落在前一半的生成元乘g:e_j^-1, h:e_j，落在后一半的乘g:e_j*y^-n', h:e_j^-1
+	sg := []*big.Int{big.NewInt(1)}
+	sh := []*big.Int{big.NewInt(1)}
+	for j := rounds - 1; j >= 0; j-- {
+		sg = append(CalVectorTimesBig(sg, eInvs[j]), CalVectorTimesBig(sg, mulInP(es[j], yInvs[j]))...)
+		sh = append(CalVectorTimesBig(sh, es[j]), CalVectorTimesBig(sh, eInvs[j])...)
+	}
+
+	//e^2*P + e*A' + B = e*r'*g + e*s'*h + r'*y*s'*G + delta'*H，其中
+	//P = A - z*<1,g> + <d∘y← + z,h> + z^2*y^(n+1)*V + zeta*G + Σ(e_j^2*L_j + e_j^-2*R_j)
+	//把除B以外的项移到同一侧，用一次多标量乘法算出应当等于B的点
+	dy, zeta, zy := bpPlusParams(y, z, n)
+	minusE2 := negBig(e2)
+	var points []Point
+	var scalars []*big.Int
+	for i := int64(0); i < n; i++ {
+		points = append(points, GVector[i], HVector[i])
+		scalars = append(scalars, addInP(mulInP(mulInP(e, proof.r1), sg[i]), mulInP(e2, z)))
+		scalars = append(scalars, subInP(mulInP(mulInP(e, proof.s1), sh[i]), mulInP(e2, addInP(dy[i], z))))
+	}
+	points = append(points, G, H, proof.A, V, proof.A1)
+	scalars = append(scalars, subInP(mulInP(mulInP(proof.r1, y), proof.s1), mulInP(e2, zeta)), proof.delta1, minusE2, mulInP(minusE2, zy), negBig(e))
+	for j := 0; j < rounds; j++ {
+		points = append(points, proof.L[j], proof.R[j])
+		scalars = append(scalars, mulInP(minusE2, mulInP(es[j], es[j])), mulInP(minusE2, mulInP(eInvs[j], eInvs[j])))
+	}
+	if !IsEqual(CommitSingleVector(points, scalars), proof.B) {
+		return ErrWIPFailed
+	}
+	return nil
+}
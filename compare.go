@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+)
+
+//比较证明：证明承诺Va,Vb中的值满足a < b（或a <= b），而不打开任何一个承诺
+//D = Vb - Va - G是对b-a-1的承诺，盲因子为gammaB-gammaA，证明D中的值在[0,2^n)中即可得到a < b
+//a <= b时不减G，直接证明Vb - Va中的b-a在[0,2^n)中
+//a,b本身需要已知在[0,2^n)中（例如各自已有范围证明），否则模N的回绕会让结论失效
+//verifier必须指定n，并且2^(n+1) <= N：a > b时差模N后至少是N-2^n，这样它才不会落在[0,2^n)中
+
+type ComparisonProof struct {
+	Va, Vb Point
+	Range  RangeProof
+}
+
+//比较关系写入transcript，a < b的证明不能当作a <= b的证明使用，反之亦然
+const (
+	relationLess        byte = 1
+	relationLessOrEqual byte = 2
+)
+
+//由两个承诺计算D = Vb - Va - G，a <= b时为Vb - Va
+func comparisonCommitment(G Point, Va Point, Vb Point, relation byte) Point {
+	minusOne := negBig(big.NewInt(1)).Bytes()
+	if relation == relationLessOrEqual {
+		return MultiCommit(Vb, CommitSingle(Va, minusOne))
+	}
+	return MultiCommit(Vb, Commit(Va, G, minusOne, minusOne))
+}
+
+//判断n是否可以用于比较证明
+func isComparisonWidth(n int64) bool {
+	return IsValidRangeWidth(n) && big.NewInt(0).Lsh(big.NewInt(1), uint(n+1)).Cmp(curve.N) <= 0
+}
+
+func appendComparisonStatement(transcript *Transcript, Va Point, Vb Point, relation byte) {
+	transcript.AppendBytes("comparison", []byte{relation})
+	transcript.AppendPoint("Va", Va)
+	transcript.AppendPoint("Vb", Vb)
+}
+
+//生成a < b的证明，Va = Commit(a,gammaA)，Vb = Commit(b,gammaB)
+func ProveLessThan(mode RangeProofMode, G Point, H Point, GVector []Point, HVector []Point, a *big.Int, gammaA *big.Int, b *big.Int, gammaB *big.Int, n int64, transcript *Transcript) (ComparisonProof, error) {
+	return proveComparison(mode, G, H, GVector, HVector, a, gammaA, b, gammaB, n, relationLess, transcript)
+}
+
+//生成a <= b的证明
+func ProveLessOrEqual(mode RangeProofMode, G Point, H Point, GVector []Point, HVector []Point, a *big.Int, gammaA *big.Int, b *big.Int, gammaB *big.Int, n int64, transcript *Transcript) (ComparisonProof, error) {
+	return proveComparison(mode, G, H, GVector, HVector, a, gammaA, b, gammaB, n, relationLessOrEqual, transcript)
+}
+
+func proveComparison(mode RangeProofMode, G Point, H Point, GVector []Point, HVector []Point, a *big.Int, gammaA *big.Int, b *big.Int, gammaB *big.Int, n int64, relation byte, transcript *Transcript) (ComparisonProof, error) {
+	if !isComparisonWidth(n) {
+		return ComparisonProof{}, ErrInvalidRangeWidth
+	}
+	diff := big.NewInt(0).Sub(b, a)
+	if relation == relationLess {
+		diff.Sub(diff, big.NewInt(1))
+	}
+	if diff.Sign() < 0 {
+		return ComparisonProof{}, ErrValueOutOfRange
+	}
+	proof := ComparisonProof{
+		Va: CommitCT(G, H, modN(a).Bytes(), modN(gammaA).Bytes()),
+		Vb: CommitCT(G, H, modN(b).Bytes(), modN(gammaB).Bytes()),
+	}
+	appendComparisonStatement(transcript, proof.Va, proof.Vb, relation)
+	rangeProof, err := ProveRange(mode, G, H, GVector, HVector, diff, subInP(modN(gammaB), modN(gammaA)), n, transcript)
+	if err != nil {
+		return ComparisonProof{}, err
+	}
+	proof.Range = rangeProof
+	return proof, nil
+}
+
+//验证Va,Vb中的值满足a < b，证明必须是针对这两个承诺、以n位的范围生成的
+func VerifyLessThan(G Point, H Point, GVector []Point, HVector []Point, Va Point, Vb Point, n int64, proof ComparisonProof, transcript *Transcript) error {
+	return verifyComparison(G, H, GVector, HVector, Va, Vb, n, proof, relationLess, transcript)
+}
+
+//验证Va,Vb中的值满足a <= b
+func VerifyLessOrEqual(G Point, H Point, GVector []Point, HVector []Point, Va Point, Vb Point, n int64, proof ComparisonProof, transcript *Transcript) error {
+	return verifyComparison(G, H, GVector, HVector, Va, Vb, n, proof, relationLessOrEqual, transcript)
+}
+
+func verifyComparison(G Point, H Point, GVector []Point, HVector []Point, Va Point, Vb Point, n int64, proof ComparisonProof, relation byte, transcript *Transcript) error {
+	if !isComparisonWidth(n) {
+		return ErrInvalidRangeWidth
+	}
+	if !IsOnCurve(Va) || !IsOnCurve(Vb) {
+		return fmt.Errorf("%w: 承诺Va,Vb", ErrInvalidPoint)
+	}
+	if !bytes.Equal(proof.Va.Bytes(), Va.Bytes()) || !bytes.Equal(proof.Vb.Bytes(), Vb.Bytes()) {
+		return ErrCommitmentMismatch
+	}
+	appendComparisonStatement(transcript, Va, Vb, relation)
+	return VerifyRange(G, H, GVector, HVector, comparisonCommitment(G, Va, Vb, relation), n, proof.Range, transcript)
+}
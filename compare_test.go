@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+type comparisonFuncs struct {
+	name   string
+	prove  func(RangeProofMode, Point, Point, []Point, []Point, *big.Int, *big.Int, *big.Int, *big.Int, int64, *Transcript) (ComparisonProof, error)
+	verify func(Point, Point, []Point, []Point, Point, Point, int64, ComparisonProof, *Transcript) error
+}
+
+var comparisons = []comparisonFuncs{
+	{"a<b", ProveLessThan, VerifyLessThan},
+	{"a<=b", ProveLessOrEqual, VerifyLessOrEqual},
+}
+
+func TestComparison(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(16)
+	tests := []struct {
+		name    string
+		a, b    int64
+		verifyN int64
+		swap    bool
+		wantErr error
+	}{
+		{"小于", 3, 200, 8, false, nil},
+		{"零和最大值", 0, 255, 8, false, nil},
+		{"要求的范围不同", 3, 200, 16, false, ErrMalformedProof},
+		{"交换承诺", 3, 200, 8, true, ErrCommitmentMismatch},
+		{"n = 256", 3, 200, 256, false, ErrInvalidRangeWidth},
+	}
+	for _, cmp := range comparisons {
+		for _, test := range tests {
+			t.Run(cmp.name+"/"+test.name, func(t *testing.T) {
+				a, b := big.NewInt(test.a), big.NewInt(test.b)
+				gammaA, gammaB := GenerateRandomScalar(), GenerateRandomScalar()
+				proof, err := cmp.prove(ModeBulletproofs, G, H, GVector, HVector, a, gammaA, b, gammaB, 8, newTranscript("compare test"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				Va := CommitCT(G, H, a.Bytes(), gammaA.Bytes())
+				Vb := CommitCT(G, H, b.Bytes(), gammaB.Bytes())
+				if test.swap {
+					Va, Vb = Vb, Va
+				}
+				err = cmp.verify(G, H, GVector, HVector, Va, Vb, test.verifyN, proof, newTranscript("compare test"))
+				if !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil) {
+					t.Fatalf("err = %v，应为%v", err, test.wantErr)
+				}
+			})
+		}
+	}
+}
+
+func TestComparisonEqualValues(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(8)
+	a, gammaA, gammaB := big.NewInt(9), GenerateRandomScalar(), GenerateRandomScalar()
+	if _, err := ProveLessThan(ModeBulletproofs, G, H, GVector, HVector, a, gammaA, a, gammaB, 8, newTranscript("compare test")); !errors.Is(err, ErrValueOutOfRange) {
+		t.Fatalf("a = b时a < b: err = %v", err)
+	}
+	proof, err := ProveLessOrEqual(ModeBulletproofsPlus, G, H, GVector, HVector, a, gammaA, a, gammaB, 8, newTranscript("compare test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyLessOrEqual(G, H, GVector, HVector, proof.Va, proof.Vb, 8, proof, newTranscript("compare test")); err != nil {
+		t.Fatal(err)
+	}
+	//a <= b的证明不能当作a < b的证明
+	if err := VerifyLessThan(G, H, GVector, HVector, proof.Va, proof.Vb, 8, proof, newTranscript("compare test")); !IsInvalidProof(err) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestComparisonTampered(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(8)
+	a, b := big.NewInt(10), big.NewInt(20)
+	gammaA, gammaB := GenerateRandomScalar(), GenerateRandomScalar()
+	proof, err := ProveLessThan(ModeBulletproofs, G, H, GVector, HVector, a, gammaA, b, gammaB, 8, newTranscript("compare test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof.Range.taux = addInP(proof.Range.taux, big.NewInt(1))
+	err = VerifyLessThan(G, H, GVector, HVector, proof.Va, proof.Vb, 8, proof, newTranscript("compare test"))
+	if !IsInvalidProof(err) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestComparisonWidth(t *testing.T) {
+	for n, want := range map[int64]bool{1: true, 64: true, 128: true, 129: false, 255: false, 256: false} {
+		if isComparisonWidth(n) != want {
+			t.Errorf("isComparisonWidth(%d) = %v", n, !want)
+		}
+	}
+}
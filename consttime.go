@@ -0,0 +1,363 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"github.com/decred/dcrd/dcrec/secp256k1/v3"
+	"math/big"
+	"math/bits"
+)
+
+//常数时间的代码路径，prover中所有涉及秘密值（v的二进制分解、盲化因子、标量运算）的操作都走这里
+//verifier只处理公开数据，继续使用pedersonCommit.go中的变长时间运算
+
+//射影坐标(X:Y:Z)下的点，使用完备加法公式，无穷远点为(0:1:0)
+type ctPoint struct {
+	x, y, z secp256k1.FieldVal
+}
+
+//secp256k1中的3b，用于完备加法公式
+const ctB3 = 21
+
+func feAdd(r, a, b *secp256k1.FieldVal) {
+	r.Add2(a, b).Normalize()
+}
+
+func feSub(r, a, b *secp256k1.FieldVal) {
+	var t secp256k1.FieldVal
+	t.NegateVal(b, 1)
+	r.Add2(a, &t).Normalize()
+}
+
+func feMul(r, a, b *secp256k1.FieldVal) {
+	r.Mul2(a, b).Normalize()
+}
+
+func feMulInt(r, a *secp256k1.FieldVal, k uint8) {
+	r.Set(a).MulInt(k).Normalize()
+}
+
+//把Point转换为射影坐标，(0,0)视为无穷远点
+//基点都是公开的，这里的分支不涉及秘密
+func toCTPoint(p Point) ctPoint {
+	var q ctPoint
+	if p.x == nil || p.y == nil || (p.x.Sign() == 0 && p.y.Sign() == 0) {
+		q.y.SetInt(1)
+		return q
+	}
+	q.x.SetByteSlice(p.x.Bytes())
+	q.y.SetByteSlice(p.y.Bytes())
+	q.z.SetInt(1)
+	return q
+}
+
+//转换为仿射坐标，无穷远点返回(0,0)，与curve.ScalarMult的约定一致
+func (p *ctPoint) affine() Point {
+	var zInv, x, y secp256k1.FieldVal
+	zInv.Set(&p.z).Inverse().Normalize()
+	feMul(&x, &p.x, &zInv)
+	feMul(&y, &p.y, &zInv)
+	return Point{
+		x: big.NewInt(0).SetBytes(x.Bytes()[:]),
+		y: big.NewInt(0).SetBytes(y.Bytes()[:]),
+	}
+}
+
+//完备加法公式（Renes-Costello-Batina，a=0），对P=Q和无穷远点同样成立，没有分支
+func ctAdd(p, q *ctPoint) ctPoint {
+	var t0, t1, t2, t3, t4, x3, y3, z3 secp256k1.FieldVal
+	feMul(&t0, &p.x, &q.x)
+	feMul(&t1, &p.y, &q.y)
+	feMul(&t2, &p.z, &q.z)
+	feAdd(&t3, &p.x, &p.y)
+	feAdd(&t4, &q.x, &q.y)
+	feMul(&t3, &t3, &t4)
+	feAdd(&t4, &t0, &t1)
+	feSub(&t3, &t3, &t4)
+	feAdd(&t4, &p.y, &p.z)
+	feAdd(&x3, &q.y, &q.z)
+	feMul(&t4, &t4, &x3)
+	feAdd(&x3, &t1, &t2)
+	feSub(&t4, &t4, &x3)
+	feAdd(&x3, &p.x, &p.z)
+	feAdd(&y3, &q.x, &q.z)
+	feMul(&x3, &x3, &y3)
+	feAdd(&y3, &t0, &t2)
+	feSub(&y3, &x3, &y3)
+	feAdd(&x3, &t0, &t0)
+	feAdd(&t0, &x3, &t0)
+	feMulInt(&t2, &t2, ctB3)
+	feAdd(&z3, &t1, &t2)
+	feSub(&t1, &t1, &t2)
+	feMulInt(&y3, &y3, ctB3)
+	feMul(&x3, &t4, &y3)
+	feMul(&t2, &t3, &t1)
+	feSub(&x3, &t2, &x3)
+	feMul(&y3, &y3, &t0)
+	feMul(&t1, &t1, &z3)
+	feAdd(&y3, &t1, &y3)
+	feMul(&t0, &t0, &t3)
+	feMul(&z3, &z3, &t4)
+	feAdd(&z3, &z3, &t0)
+	return ctPoint{x: x3, y: y3, z: z3}
+}
+
+//bit为1时交换a,b，为0时不变，通过字节级的条件拷贝实现
+func ctSwapField(a, b *secp256k1.FieldVal, bit int) {
+	ab := a.Bytes()
+	bb := b.Bytes()
+	var t [32]byte
+	copy(t[:], ab[:])
+	subtle.ConstantTimeCopy(bit, ab[:], bb[:])
+	subtle.ConstantTimeCopy(bit, bb[:], t[:])
+	a.SetBytes(ab)
+	b.SetBytes(bb)
+}
+
+func ctSwap(p, q *ctPoint, bit int) {
+	ctSwapField(&p.x, &q.x, bit)
+	ctSwapField(&p.y, &q.y, bit)
+	ctSwapField(&p.z, &q.z, bit)
+}
+
+//Montgomery ladder计算k*P，无论k取何值都固定处理256位
+func ctScalarMult(p Point, k *secp256k1.ModNScalar) ctPoint {
+	var r0 ctPoint
+	r0.y.SetInt(1)
+	r1 := toCTPoint(p)
+
+	kb := k.Bytes()
+	for i := 0; i < 256; i++ {
+		bit := int(kb[i/8]>>(7-uint(i%8))) & 1
+		ctSwap(&r0, &r1, bit)
+		r1 = ctAdd(&r0, &r1)
+		r0 = ctAdd(&r0, &r0)
+		ctSwap(&r0, &r1, bit)
+	}
+	return r0
+}
+
+//把*big.Int按固定的32字节转换为标量，避免Bytes()的长度随数值变化
+func bigToScalar(a *big.Int) *secp256k1.ModNScalar {
+	var s secp256k1.ModNScalar
+	var buf [32]byte
+	s.SetByteSlice(a.FillBytes(buf[:]))
+	return &s
+}
+
+//常数时间的pederson承诺，P = v*G + r*H
+func CommitCT(G Point, H Point, secret []byte, blinding []byte) Point {
+	var v, r secp256k1.ModNScalar
+	v.SetByteSlice(secret)
+	r.SetByteSlice(blinding)
+	vG := ctScalarMult(G, &v)
+	rH := ctScalarMult(H, &r)
+	commit := ctAdd(&vG, &rH)
+	return commit.affine()
+}
+
+//常数时间地为一个数值提供承诺
+func CommitSingleCT(H Point, secret []byte) Point {
+	var v secp256k1.ModNScalar
+	v.SetByteSlice(secret)
+	commit := ctScalarMult(H, &v)
+	return commit.affine()
+}
+
+//常数时间地将两个承诺相乘
+func MultiCommitCT(commit0 Point, commit1 Point) Point {
+	p := toCTPoint(commit0)
+	q := toCTPoint(commit1)
+	commit := ctAdd(&p, &q)
+	return commit.affine()
+}
+
+//常数时间地为矢量提供承诺
+func CommitVectorsCT(G_vector []Point, H_vector []Point, Secret1 []*big.Int, Secret2 []*big.Int) Point {
+	var commit ctPoint
+	commit.y.SetInt(1)
+	for i := 0; i < len(G_vector); i++ {
+		l := ctScalarMult(G_vector[i], bigToScalar(Secret1[i]))
+		r := ctScalarMult(H_vector[i], bigToScalar(Secret2[i]))
+		commit = ctAdd(&commit, &l)
+		commit = ctAdd(&commit, &r)
+	}
+	return commit.affine()
+}
+
+//常数时间地为一个矢量提供承诺
+func CommitSingleVectorCT(H_vector []Point, secret []*big.Int) Point {
+	var commit ctPoint
+	commit.y.SetInt(1)
+	for i := 0; i < len(H_vector); i++ {
+		r := ctScalarMult(H_vector[i], bigToScalar(secret[i]))
+		commit = ctAdd(&commit, &r)
+	}
+	return commit.affine()
+}
+
+//常数时间地生成a_L
+//把v按固定的32字节展开后逐位取出二进制，不根据某一位的取值分支，也不修改v
+//高于n位的部分按位累积后统一判断，只有是否超出范围这一结果会被泄露
+func GenerateA_LCT(v *big.Int, n int64) ([]*big.Int, error) {
+	if n <= 0 || n > 256 || v.Sign() < 0 || v.BitLen() > 256 {
+		return nil, ErrValueOutOfRange
+	}
+	var buf [32]byte
+	v.FillBytes(buf[:])
+
+	var a_L []*big.Int
+	var high byte
+	for i := int64(0); i < 256; i++ {
+		bit := (buf[31-i/8] >> uint(i%8)) & 1
+		if i >= n {
+			high |= bit
+			continue
+		}
+		var s secp256k1.ModNScalar
+		s.SetInt(uint32(bit))
+		b := s.Bytes()
+		a_L = append(a_L, big.NewInt(0).SetBytes(b[:]))
+	}
+	if high != 0 {
+		return nil, ErrValueOutOfRange
+	}
+	return a_L, nil
+}
+
+//标量的Montgomery表示，4个64位的limb，低位在前
+type montScalar [4]uint64
+
+//群的阶N，以及Montgomery乘法所需的常量
+var (
+	montN     montScalar
+	montNInv0 uint64     //-N^(-1) mod 2^64
+	montR2    montScalar //2^512 mod N
+	montOne   montScalar //2^256 mod N，即1的Montgomery表示
+)
+
+func init() {
+	N := secp256k1.S256().N
+	montN = bigToMont(N)
+	two64 := big.NewInt(0).Lsh(big.NewInt(1), 64)
+	inv := big.NewInt(0).ModInverse(big.NewInt(0).Mod(N, two64), two64)
+	montNInv0 = big.NewInt(0).Sub(two64, inv).Uint64()
+	montR2 = bigToMont(big.NewInt(0).Mod(big.NewInt(0).Lsh(big.NewInt(1), 512), N))
+	montOne = bigToMont(big.NewInt(0).Mod(big.NewInt(0).Lsh(big.NewInt(1), 256), N))
+}
+
+//只用于初始化公开的常量
+func bigToMont(a *big.Int) montScalar {
+	var b [32]byte
+	a.FillBytes(b[:])
+	return bytesToMont(&b)
+}
+
+func bytesToMont(b *[32]byte) montScalar {
+	var m montScalar
+	for i := 0; i < 4; i++ {
+		m[i] = binary.BigEndian.Uint64(b[24-8*i : 32-8*i])
+	}
+	return m
+}
+
+func (m *montScalar) bytes() [32]byte {
+	var b [32]byte
+	for i := 0; i < 4; i++ {
+		binary.BigEndian.PutUint64(b[24-8*i:32-8*i], m[i])
+	}
+	return b
+}
+
+//Montgomery乘法(CIOS)，计算a*b*2^(-256) mod N，要求a,b<N
+//只使用bits.Mul64/Add64/Sub64，最后的条件减法用掩码代替分支
+func montMul(a, b *montScalar) montScalar {
+	var t [6]uint64
+	for i := 0; i < 4; i++ {
+		var c, carry, hi, lo uint64
+		for j := 0; j < 4; j++ {
+			hi, lo = bits.Mul64(a[j], b[i])
+			lo, carry = bits.Add64(lo, t[j], 0)
+			hi += carry
+			lo, carry = bits.Add64(lo, c, 0)
+			hi += carry
+			t[j], c = lo, hi
+		}
+		t[4], carry = bits.Add64(t[4], c, 0)
+		t[5] = carry
+
+		m := t[0] * montNInv0
+		hi, lo = bits.Mul64(m, montN[0])
+		_, carry = bits.Add64(lo, t[0], 0)
+		c = hi + carry
+		for j := 1; j < 4; j++ {
+			hi, lo = bits.Mul64(m, montN[j])
+			lo, carry = bits.Add64(lo, t[j], 0)
+			hi += carry
+			lo, carry = bits.Add64(lo, c, 0)
+			hi += carry
+			t[j-1], c = lo, hi
+		}
+		t[3], carry = bits.Add64(t[4], c, 0)
+		t[4] = t[5] + carry
+	}
+
+	var r montScalar
+	var borrow uint64
+	for j := 0; j < 4; j++ {
+		r[j], borrow = bits.Sub64(t[j], montN[j], borrow)
+	}
+	_, borrow = bits.Sub64(t[4], 0, borrow)
+	mask := -borrow
+	for j := 0; j < 4; j++ {
+		r[j] = (t[j] & mask) | (r[j] &^ mask)
+	}
+	return r
+}
+
+func scalarToMont(a *secp256k1.ModNScalar) montScalar {
+	b := a.Bytes()
+	m := bytesToMont(&b)
+	return montMul(&m, &montR2)
+}
+
+func montToScalar(m *montScalar) *secp256k1.ModNScalar {
+	one := montScalar{1}
+	r := montMul(m, &one)
+	b := r.bytes()
+	var s secp256k1.ModNScalar
+	s.SetBytes(&b)
+	return &s
+}
+
+//常数时间的标量乘法a*b mod N
+//secp256k1 v3.0.0的ModNScalar.Mul在部分输入下会给出错误的结果（复现见consttime_test.go），这里不依赖它
+func scalarMulCT(a, b *secp256k1.ModNScalar) *secp256k1.ModNScalar {
+	am := scalarToMont(a)
+	bm := scalarToMont(b)
+	r := montMul(&am, &bm)
+	return montToScalar(&r)
+}
+
+//常数时间地求逆元，利用费马小定理计算a^(N-2)
+//指数N-2是公开的，所以按位平方-乘不会泄露a
+func inverseCT(a *secp256k1.ModNScalar) *secp256k1.ModNScalar {
+	var e [32]byte
+	big.NewInt(0).Sub(curve.N, big.NewInt(2)).FillBytes(e[:])
+
+	x := scalarToMont(a)
+	r := montOne
+	for i := 0; i < 256; i++ {
+		r = montMul(&r, &r)
+		if (e[i/8]>>(7-uint(i%8)))&1 == 1 {
+			r = montMul(&r, &x)
+		}
+	}
+	return montToScalar(&r)
+}
+
+//常数时间地对*big.Int类型的数字求逆元
+func inverseBigCT(a *big.Int) *big.Int {
+	b := inverseCT(bigToScalar(a)).Bytes()
+	return big.NewInt(0).SetBytes(b[:])
+}
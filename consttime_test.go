@@ -0,0 +1,215 @@
+package main
+
+import (
+	"github.com/decred/dcrd/dcrec/secp256k1/v3"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func hexBig(s string) *big.Int {
+	b, _ := big.NewInt(0).SetString(s, 16)
+	return b
+}
+
+//汉明重量和长度差异很大的几组秘密值
+func timingSecrets() [][]byte {
+	max := big.NewInt(0).Sub(curve.N, big.NewInt(1))
+	return [][]byte{
+		{},
+		{1},
+		{0x80, 0, 0, 0},
+		big.NewInt(0).Rsh(max, 128).Bytes(),
+		max.Bytes(),
+	}
+}
+
+func TestCommitCTMatchesCommit(t *testing.T) {
+	G, H := GeneratePoint(), GeneratePoint()
+	max := big.NewInt(0).Sub(curve.N, big.NewInt(1))
+	tests := []struct {
+		name             string
+		secret, blinding *big.Int
+	}{
+		{"零", big.NewInt(0), big.NewInt(0)},
+		{"一", big.NewInt(1), big.NewInt(1)},
+		{"N-1", max, max},
+		{"随机", GenerateRandomScalar(), GenerateRandomScalar()},
+		{"秘密为零", big.NewInt(0), GenerateRandomScalar()},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			want := Commit(G, H, test.secret.Bytes(), test.blinding.Bytes())
+			if got := CommitCT(G, H, test.secret.Bytes(), test.blinding.Bytes()); !IsEqual(got, want) {
+				t.Fatal("CommitCT与Commit的结果不同")
+			}
+			want = CommitSingle(H, test.secret.Bytes())
+			if got := CommitSingleCT(H, test.secret.Bytes()); !IsEqual(got, want) {
+				t.Fatal("CommitSingleCT与CommitSingle的结果不同")
+			}
+		})
+	}
+}
+
+func TestMultiCommitCTInfinity(t *testing.T) {
+	G := GeneratePoint()
+	x := GenerateRandomScalar()
+	P := CommitSingleCT(G, x.Bytes())
+	minusP := CommitSingleCT(G, negBig(x).Bytes())
+	if sum := MultiCommitCT(P, minusP); sum.x.Sign() != 0 || sum.y.Sign() != 0 {
+		t.Fatal("P + (-P)应为无穷远点(0,0)")
+	}
+	if sum := MultiCommitCT(P, P); !IsEqual(sum, MultiCommit(P, P)) {
+		t.Fatal("P + P的结果不同")
+	}
+}
+
+func TestCommitVectorsCT(t *testing.T) {
+	_, _, GVector, HVector := testGenerators(8)
+	a, b := GenerateRandomVector(8), GenerateRandomVector(8)
+	a[3] = big.NewInt(0)
+	if !IsEqual(CommitVectorsCT(GVector, HVector, a, b), CommitVectors(GVector, HVector, a, b)) {
+		t.Fatal("CommitVectorsCT与CommitVectors的结果不同")
+	}
+	if !IsEqual(CommitSingleVectorCT(HVector, b), CommitSingleVector(HVector, b)) {
+		t.Fatal("CommitSingleVectorCT与CommitSingleVector的结果不同")
+	}
+}
+
+func TestScalarMulCT(t *testing.T) {
+	max := big.NewInt(0).Sub(curve.N, big.NewInt(1))
+	bad := hexBig("2aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa74727a26728c1ab49ff8651778090ae0")
+	tests := []struct {
+		name string
+		a, b *big.Int
+	}{
+		{"零", big.NewInt(0), GenerateRandomScalar()},
+		{"一", big.NewInt(1), max},
+		{"N-1的平方", max, max},
+		{"ModNScalar.Mul出错的输入", bad, bad},
+		{"随机", GenerateRandomScalar(), GenerateRandomScalar()},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			want := big.NewInt(0).Mod(big.NewInt(0).Mul(test.a, test.b), curve.N)
+			if got := mulInP(test.a, test.b); got.Cmp(want) != 0 {
+				t.Fatalf("mulInP = %x，应为%x", got, want)
+			}
+		})
+	}
+}
+
+//复现secp256k1 v3.0.0中ModNScalar.Mul的错误，这是scalarMulCT不依赖它的原因
+//如果依赖升级后这个测试失败，说明错误已经修复
+func TestModNScalarMulReproducer(t *testing.T) {
+	bad := hexBig("2aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa74727a26728c1ab49ff8651778090ae0")
+	var a secp256k1.ModNScalar
+	a.SetByteSlice(bad.Bytes())
+	a.Mul(&a)
+	got := ScalarToBig(&a)
+	want := big.NewInt(0).Mod(big.NewInt(0).Mul(bad, bad), curve.N)
+	if got.Cmp(want) == 0 {
+		t.Fatal("ModNScalar.Mul对该输入给出了正确的结果")
+	}
+	if ct := ScalarToBig(scalarMulCT(bigToScalar(bad), bigToScalar(bad))); ct.Cmp(want) != 0 {
+		t.Fatal("scalarMulCT的结果错误")
+	}
+}
+
+func TestInverseBigCT(t *testing.T) {
+	for _, a := range []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(0).Sub(curve.N, big.NewInt(1)), GenerateRandomScalar()} {
+		want := big.NewInt(0).ModInverse(a, curve.N)
+		if got := inverseBigCT(a); got.Cmp(want) != 0 {
+			t.Fatalf("%x的逆元 = %x，应为%x", a, got, want)
+		}
+	}
+}
+
+func TestGenerateA_LCT(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       *big.Int
+		n       int64
+		wantErr bool
+	}{
+		{"零", big.NewInt(0), 4, false},
+		{"最大值", big.NewInt(15), 4, false},
+		{"超出范围", big.NewInt(16), 4, true},
+		{"负数", big.NewInt(-1), 4, true},
+		{"128位", big.NewInt(0).Lsh(big.NewInt(1), 127), 128, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			before := big.NewInt(0).Set(test.v)
+			aL, err := GenerateA_LCT(test.v, test.n)
+			if test.v.Cmp(before) != 0 {
+				t.Fatal("GenerateA_LCT修改了v")
+			}
+			if (err != nil) != test.wantErr {
+				t.Fatalf("err = %v", err)
+			}
+			if err != nil {
+				return
+			}
+			sum := big.NewInt(0)
+			for i := len(aL) - 1; i >= 0; i-- {
+				sum.Lsh(sum, 1).Add(sum, aL[i])
+			}
+			if int64(len(aL)) != test.n || sum.Cmp(test.v) != 0 {
+				t.Fatal("a_L不是v的二进制分解")
+			}
+		})
+	}
+}
+
+//测量不同秘密输入下承诺运算耗时的离散程度
+//返回各输入平均耗时的变异系数（标准差/均值）
+func measureCommitTiming(commit func(Point, Point, []byte, []byte) Point, samples int) float64 {
+	G, H := GeneratePoint(), GeneratePoint()
+	var means []float64
+	for _, s := range timingSecrets() {
+		start := time.Now()
+		for i := 0; i < samples; i++ {
+			commit(G, H, s, s)
+		}
+		means = append(means, float64(time.Since(start))/float64(samples))
+	}
+	var mean, variance float64
+	for _, value := range means {
+		mean += value
+	}
+	mean /= float64(len(means))
+	for _, value := range means {
+		variance += (value - mean) * (value - mean)
+	}
+	return math.Sqrt(variance/float64(len(means))) / mean
+}
+
+//go test -run CommitTiming -v 输出两种实现的耗时变异系数，耗时受机器负载影响，这里只记录不做判断
+func TestCommitTiming(t *testing.T) {
+	if testing.Short() {
+		t.Skip("耗时测量")
+	}
+	t.Logf("常数时间承诺的耗时变异系数: %.4f", measureCommitTiming(CommitCT, 50))
+	t.Logf("变长时间承诺的耗时变异系数: %.4f", measureCommitTiming(Commit, 50))
+}
+
+func benchmarkCommit(b *testing.B, commit func(Point, Point, []byte, []byte) Point) {
+	G, H := GeneratePoint(), GeneratePoint()
+	for i, s := range timingSecrets() {
+		b.Run(string(rune('0'+i)), func(b *testing.B) {
+			for j := 0; j < b.N; j++ {
+				commit(G, H, s, s)
+			}
+		})
+	}
+}
+
+func BenchmarkCommitCT(b *testing.B) {
+	benchmarkCommit(b, CommitCT)
+}
+
+func BenchmarkCommit(b *testing.B) {
+	benchmarkCommit(b, Commit)
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/decred/dcrd/dcrec/secp256k1/v3"
+	"github.com/decred/dcrd/dcrec/secp256k1/v3/schnorr"
+	"math/big"
+)
+
+//属性凭证：发行者为每个属性生成承诺C_i = a_i*G + r_i*H，并用schnorr签名所有属性名和承诺
+//持有者出示时不打开承诺，而是对导出的承诺做范围证明：
+//a >= min：C - min*G是对a-min的承诺；a <= max：max*G - C是对max-a的承诺，盲因子为-r
+//验证者检查发行者的签名，再用同样的方式导出承诺并验证范围证明
+//属性值和max-min都必须小于2^n
+
+type CredentialParams struct {
+	Mode             RangeProofMode
+	G, H             Point
+	GVector, HVector []Point
+	N                int64
+}
+
+type Issuer struct {
+	PK   Point
+	priv *secp256k1.PrivateKey
+}
+
+type Attribute struct {
+	Name  string
+	Value *big.Int
+}
+
+type Credential struct {
+	Names       []string
+	Commitments []Point
+	Signature   *schnorr.Signature
+}
+
+//持有者保存的属性打开
+type CredentialSecret struct {
+	Values []*big.Int
+	Gammas []*big.Int
+}
+
+//对属性Name满足Min <= a <= Max的证明，Min或Max为nil表示该侧不限制
+type Presentation struct {
+	Credential Credential
+	Name       string
+	Min, Max   *big.Int
+	Lower      RangeProof
+	Upper      RangeProof
+}
+
+func (issuer *Issuer) New() error {
+	priv, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		return err
+	}
+	issuer.priv = priv
+	issuer.PK = Point{x: priv.PubKey().X(), y: priv.PubKey().Y()}
+	return nil
+}
+
+//签名的消息覆盖所有属性名和承诺
+func credentialMessage(names []string, commitments []Point) []byte {
+	h := sha256.New()
+	writeWithLength(h.Write, []byte("attribute credential"))
+	for i := range names {
+		writeWithLength(h.Write, []byte(names[i]))
+		writeWithLength(h.Write, commitments[i].Bytes())
+	}
+	return h.Sum(nil)
+}
+
+//为属性生成承诺并签名，返回凭证和交给持有者的打开
+func (issuer Issuer) Issue(params CredentialParams, attributes []Attribute) (Credential, CredentialSecret, error) {
+	var credential Credential
+	var secret CredentialSecret
+	names := make(map[string]bool)
+	for _, attribute := range attributes {
+		if names[attribute.Name] {
+			return Credential{}, CredentialSecret{}, fmt.Errorf("%w: 属性%q重复", ErrMalformedProof, attribute.Name)
+		}
+		names[attribute.Name] = true
+		gamma := GenerateRandomScalar()
+		credential.Names = append(credential.Names, attribute.Name)
+		credential.Commitments = append(credential.Commitments, CommitCT(params.G, params.H, modN(attribute.Value).Bytes(), gamma.Bytes()))
+		secret.Values = append(secret.Values, big.NewInt(0).Set(attribute.Value))
+		secret.Gammas = append(secret.Gammas, gamma)
+	}
+	signature, err := schnorr.Sign(issuer.priv, credentialMessage(credential.Names, credential.Commitments))
+	if err != nil {
+		return Credential{}, CredentialSecret{}, err
+	}
+	credential.Signature = signature
+	return credential, secret, nil
+}
+
+//检查发行者的签名
+func (credential Credential) Verify(issuerPK Point) error {
+	if len(credential.Names) != len(credential.Commitments) || credential.Signature == nil {
+		return fmt.Errorf("%w: 凭证格式错误", ErrMalformedProof)
+	}
+	for _, C := range credential.Commitments {
+		if !IsOnCurve(C) {
+			return fmt.Errorf("%w: %v", ErrMalformedProof, ErrInvalidPoint)
+		}
+	}
+	pub, err := secp256k1.ParsePubKey(issuerPK.Bytes())
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPoint, err)
+	}
+	if !credential.Signature.Verify(credentialMessage(credential.Names, credential.Commitments), pub) {
+		return ErrSignatureFailed
+	}
+	return nil
+}
+
+func (credential Credential) find(name string) (int, error) {
+	for i, value := range credential.Names {
+		if value == name {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrAttributeNotFound, name)
+}
+
+func presentationTranscript(credential Credential, name string, min *big.Int, max *big.Int) *Transcript {
+	var t Transcript
+	t.New("credential presentation")
+	t.AppendBytes("credential", credentialMessage(credential.Names, credential.Commitments))
+	t.AppendBytes("name", []byte(name))
+	for _, bound := range []*big.Int{min, max} {
+		if bound == nil {
+			t.AppendBytes("bound", nil)
+		} else {
+			t.AppendScalar("bound", bound)
+		}
+	}
+	return &t
+}
+
+//导出的承诺：C - min*G和max*G - C
+func lowerCommitment(G Point, C Point, min *big.Int) Point {
+	return MultiCommit(C, CommitSingle(G, negBig(modN(min)).Bytes()))
+}
+
+func upperCommitment(G Point, C Point, max *big.Int) Point {
+	return MultiCommit(CommitSingle(G, modN(max).Bytes()), CommitSingle(C, negBig(big.NewInt(1)).Bytes()))
+}
+
+//持有者证明属性name在[min,max]中
+func (credential Credential) Present(params CredentialParams, secret CredentialSecret, name string, min *big.Int, max *big.Int) (Presentation, error) {
+	i, err := credential.find(name)
+	if err != nil {
+		return Presentation{}, err
+	}
+	if min == nil && max == nil {
+		return Presentation{}, fmt.Errorf("%w: 至少需要一个边界", ErrMalformedProof)
+	}
+	value, gamma := secret.Values[i], secret.Gammas[i]
+	presentation := Presentation{Credential: credential, Name: name, Min: min, Max: max}
+	transcript := presentationTranscript(credential, name, min, max)
+	if min != nil {
+		lower, err := ProveRange(params.Mode, params.G, params.H, params.GVector, params.HVector, big.NewInt(0).Sub(value, min), gamma, params.N, transcript)
+		if err != nil {
+			return Presentation{}, err
+		}
+		presentation.Lower = lower
+	}
+	if max != nil {
+		upper, err := ProveRange(params.Mode, params.G, params.H, params.GVector, params.HVector, big.NewInt(0).Sub(max, value), negBig(gamma), params.N, transcript)
+		if err != nil {
+			return Presentation{}, err
+		}
+		presentation.Upper = upper
+	}
+	return presentation, nil
+}
+
+//验证者检查签名和范围，min,max是验证者要求的边界，必须与出示的一致
+func VerifyPresentation(params CredentialParams, issuerPK Point, presentation Presentation, name string, min *big.Int, max *big.Int) error {
+	if presentation.Name != name || !sameBound(presentation.Min, min) || !sameBound(presentation.Max, max) {
+		return fmt.Errorf("%w: 出示的属性或边界与要求不符", ErrMalformedProof)
+	}
+	if min == nil && max == nil {
+		return fmt.Errorf("%w: 至少需要一个边界", ErrMalformedProof)
+	}
+	credential := presentation.Credential
+	if err := credential.Verify(issuerPK); err != nil {
+		return err
+	}
+	i, err := credential.find(name)
+	if err != nil {
+		return err
+	}
+	C := credential.Commitments[i]
+	transcript := presentationTranscript(credential, name, min, max)
+	if min != nil {
+		if err := verifyBound(params, lowerCommitment(params.G, C, min), presentation.Lower, transcript); err != nil {
+			return err
+		}
+	}
+	if max != nil {
+		if err := verifyBound(params, upperCommitment(params.G, C, max), presentation.Upper, transcript); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func verifyBound(params CredentialParams, V Point, proof RangeProof, transcript *Transcript) error {
+	return VerifyRange(params.G, params.H, params.GVector, params.HVector, V, params.N, proof, transcript)
+}
+
+func sameBound(a *big.Int, b *big.Int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Cmp(b) == 0
+}
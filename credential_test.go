@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func testCredentialParams(n int64) CredentialParams {
+	G, H, GVector, HVector := testGenerators(n)
+	return CredentialParams{Mode: ModeBulletproofs, G: G, H: H, GVector: GVector, HVector: HVector, N: n}
+}
+
+func TestCredentialIssue(t *testing.T) {
+	params := testCredentialParams(8)
+	var issuer, other Issuer
+	if err := issuer.New(); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.New(); err != nil {
+		t.Fatal(err)
+	}
+	credential, _, err := issuer.Issue(params, []Attribute{{"age", big.NewInt(30)}, {"country", big.NewInt(86)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		pk      Point
+		tamper  func(credential *Credential)
+		wantErr error
+	}{
+		{"发行者签名", issuer.PK, nil, nil},
+		{"别的发行者", other.PK, nil, ErrSignatureFailed},
+		{"篡改属性名", issuer.PK, func(credential *Credential) { credential.Names[0] = "height" }, ErrSignatureFailed},
+		{"交换承诺", issuer.PK, func(credential *Credential) {
+			credential.Commitments[0], credential.Commitments[1] = credential.Commitments[1], credential.Commitments[0]
+		}, ErrSignatureFailed},
+		{"缺少签名", issuer.PK, func(credential *Credential) { credential.Signature = nil }, ErrMalformedProof},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tampered := credential
+			tampered.Names = append([]string{}, credential.Names...)
+			tampered.Commitments = append([]Point{}, credential.Commitments...)
+			if test.tamper != nil {
+				test.tamper(&tampered)
+			}
+			err := tampered.Verify(test.pk)
+			if !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil) {
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+		})
+	}
+	if _, _, err := issuer.Issue(params, []Attribute{{"age", big.NewInt(1)}, {"age", big.NewInt(2)}}); !errors.Is(err, ErrMalformedProof) {
+		t.Fatalf("重复的属性: err = %v", err)
+	}
+}
+
+func TestPresentation(t *testing.T) {
+	params := testCredentialParams(8)
+	var issuer Issuer
+	if err := issuer.New(); err != nil {
+		t.Fatal(err)
+	}
+	credential, secret, err := issuer.Issue(params, []Attribute{{"age", big.NewInt(30)}, {"score", big.NewInt(200)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	present := func(name string, min *big.Int, max *big.Int) Presentation {
+		presentation, err := credential.Present(params, secret, name, min, max)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return presentation
+	}
+	adult := present("age", big.NewInt(18), nil)
+	bounded := present("age", big.NewInt(18), big.NewInt(65))
+	narrow := params
+	narrow.N = 4
+	wide := testCredentialParams(16)
+	wide.G, wide.H = params.G, params.H
+	widePresentation, err := credential.Present(wide, secret, "score", big.NewInt(0), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		params       CredentialParams
+		presentation Presentation
+		attribute    string
+		min, max     *big.Int
+		wantErr      error
+	}{
+		{"只有下界", params, adult, "age", big.NewInt(18), nil, nil},
+		{"上下界", params, bounded, "age", big.NewInt(18), big.NewInt(65), nil},
+		{"要求的下界不同", params, adult, "age", big.NewInt(21), nil, ErrMalformedProof},
+		{"要求的属性不同", params, adult, "score", big.NewInt(18), nil, ErrMalformedProof},
+		{"缺少上界", params, adult, "age", big.NewInt(18), big.NewInt(65), ErrMalformedProof},
+		{"要求的范围更窄", narrow, adult, "age", big.NewInt(18), nil, ErrMalformedProof},
+		{"证明的范围更宽", params, widePresentation, "score", big.NewInt(0), nil, ErrMalformedProof},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := VerifyPresentation(test.params, issuer.PK, test.presentation, test.attribute, test.min, test.max)
+			if !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil) {
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+		})
+	}
+
+	//篡改出示中的边界后范围证明不再成立
+	forged := adult
+	forged.Min = big.NewInt(25)
+	if err := VerifyPresentation(params, issuer.PK, forged, "age", big.NewInt(25), nil); !IsInvalidProof(err) {
+		t.Fatalf("err = %v", err)
+	}
+	//不满足边界时无法生成证明
+	if _, err := credential.Present(params, secret, "age", big.NewInt(31), nil); !errors.Is(err, ErrValueOutOfRange) {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := credential.Present(params, secret, "name", big.NewInt(0), nil); !errors.Is(err, ErrAttributeNotFound) {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+)
+
+//离散对数相等证明（Chaum-Pedersen）：证明A = x*G和B = x*H使用同一个x
+//prover发送R1 = k*G，R2 = k*H，挑战c由transcript生成，回复s = k + c*x
+//verifier检查s*G = R1 + c*A，s*H = R2 + c*B
+//批量形式：对同一个x证明所有B_i = x*H_i，用transcript导出的随机系数w_i合并为H' = Σw_i*H_i，B' = Σw_i*B_i后证明一次
+
+type DLEQProof struct {
+	R1, R2 Point
+	s      *big.Int
+}
+
+func appendDLEQStatement(transcript *Transcript, G Point, H Point, A Point, B Point) {
+	transcript.AppendBytes("dleq", []byte{1})
+	transcript.AppendPoint("G", G)
+	transcript.AppendPoint("H", H)
+	transcript.AppendPoint("A", A)
+	transcript.AppendPoint("B", B)
+}
+
+//生成A = x*G，B = x*H的证明
+func ProveDLEQ(G Point, H Point, x *big.Int, transcript *Transcript) DLEQProof {
+	x = modN(x)
+	appendDLEQStatement(transcript, G, H, CommitSingleCT(G, x.Bytes()), CommitSingleCT(H, x.Bytes()))
+	k := GenerateRandomScalar()
+	proof := DLEQProof{R1: CommitSingleCT(G, k.Bytes()), R2: CommitSingleCT(H, k.Bytes())}
+	transcript.AppendPoint("R1", proof.R1)
+	transcript.AppendPoint("R2", proof.R2)
+	c := transcript.ChallengeScalar("c")
+	proof.s = addInP(k, mulInP(c, x))
+	return proof
+}
+
+//验证log_G(A) = log_H(B)
+func VerifyDLEQ(G Point, H Point, A Point, B Point, proof DLEQProof, transcript *Transcript) error {
+	for _, p := range []Point{G, H, A, B} {
+		if !IsOnCurve(p) {
+			return fmt.Errorf("%w: G,H,A,B", ErrInvalidPoint)
+		}
+	}
+	if !IsOnCurve(proof.R1) || !IsOnCurve(proof.R2) {
+		return fmt.Errorf("%w: %v", ErrMalformedProof, ErrInvalidPoint)
+	}
+	if proof.s == nil || proof.s.Sign() < 0 || proof.s.Cmp(curve.N) >= 0 {
+		return fmt.Errorf("%w: 标量超出范围", ErrMalformedProof)
+	}
+	appendDLEQStatement(transcript, G, H, A, B)
+	transcript.AppendPoint("R1", proof.R1)
+	transcript.AppendPoint("R2", proof.R2)
+	c := transcript.ChallengeScalar("c")
+	if !IsEqual(CommitSingle(G, proof.s.Bytes()), MultiCommit(proof.R1, CommitSingle(A, c.Bytes()))) {
+		return ErrDLEQFailed
+	}
+	if !IsEqual(CommitSingle(H, proof.s.Bytes()), MultiCommit(proof.R2, CommitSingle(B, c.Bytes()))) {
+		return ErrDLEQFailed
+	}
+	return nil
+}
+
+//写入所有的H_i,B_i后导出系数w_i，合并为H' = Σw_i*H_i，B' = Σw_i*B_i
+func combineDLEQ(Hs []Point, Bs []Point, transcript *Transcript) (Point, Point) {
+	transcript.AppendBytes("dleq batch", []byte{1})
+	transcript.AppendInt("pairs", int64(len(Hs)))
+	for i := range Hs {
+		transcript.AppendPoint("H", Hs[i])
+		transcript.AppendPoint("B", Bs[i])
+	}
+	var weights []*big.Int
+	for range Hs {
+		weights = append(weights, transcript.ChallengeScalar("w"))
+	}
+	return CommitSingleVector(Hs, weights), CommitSingleVector(Bs, weights)
+}
+
+//批量证明：A = x*G，且对所有i有B_i = x*H_i
+func ProveDLEQBatch(G Point, Hs []Point, x *big.Int, transcript *Transcript) (DLEQProof, error) {
+	if len(Hs) == 0 {
+		return DLEQProof{}, ErrEmptySet
+	}
+	var Bs []Point
+	for _, H := range Hs {
+		Bs = append(Bs, CommitSingleCT(H, modN(x).Bytes()))
+	}
+	H, _ := combineDLEQ(Hs, Bs, transcript)
+	return ProveDLEQ(G, H, x, transcript), nil
+}
+
+//验证批量证明
+func VerifyDLEQBatch(G Point, A Point, Hs []Point, Bs []Point, proof DLEQProof, transcript *Transcript) error {
+	if len(Hs) == 0 {
+		return ErrEmptySet
+	}
+	if len(Hs) != len(Bs) {
+		return ErrVectorLength
+	}
+	for i := range Hs {
+		if !IsOnCurve(Hs[i]) || !IsOnCurve(Bs[i]) {
+			return fmt.Errorf("%w: H_i,B_i", ErrInvalidPoint)
+		}
+	}
+	H, B := combineDLEQ(Hs, Bs, transcript)
+	return VerifyDLEQ(G, H, A, B, proof, transcript)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestDLEQ(t *testing.T) {
+	G, H := GeneratePoint(), GeneratePoint()
+	x := GenerateRandomScalar()
+	A, B := CommitSingle(G, x.Bytes()), CommitSingle(H, x.Bytes())
+	tests := []struct {
+		name    string
+		A, B    Point
+		tamper  func(proof *DLEQProof)
+		wantErr error
+	}{
+		{"诚实的证明", A, B, nil, nil},
+		{"B用了别的x", A, CommitSingle(H, GenerateRandomScalar().Bytes()), nil, ErrDLEQFailed},
+		{"交换A和B", B, A, nil, ErrDLEQFailed},
+		{"篡改s", A, B, func(proof *DLEQProof) { proof.s = addInP(proof.s, big.NewInt(1)) }, ErrDLEQFailed},
+		{"篡改R2", A, B, func(proof *DLEQProof) { proof.R2 = MultiCommit(proof.R2, H) }, ErrDLEQFailed},
+		{"s超出Zp", A, B, func(proof *DLEQProof) { proof.s = big.NewInt(0).Set(curve.N) }, ErrMalformedProof},
+		{"R1不在曲线上", A, B, func(proof *DLEQProof) { proof.R1 = Point{x: big.NewInt(1), y: big.NewInt(1)} }, ErrMalformedProof},
+		{"B是无穷远点", A, Point{x: big.NewInt(0), y: big.NewInt(0)}, nil, ErrInvalidPoint},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			proof := ProveDLEQ(G, H, x, newTranscript("dleq test"))
+			if test.tamper != nil {
+				test.tamper(&proof)
+			}
+			err := VerifyDLEQ(G, H, test.A, test.B, proof, newTranscript("dleq test"))
+			if !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil) {
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestDLEQBatch(t *testing.T) {
+	G := GeneratePoint()
+	Hs := []Point{GeneratePoint(), GeneratePoint(), GeneratePoint()}
+	x := GenerateRandomScalar()
+	A := CommitSingle(G, x.Bytes())
+	var Bs []Point
+	for _, H := range Hs {
+		Bs = append(Bs, CommitSingle(H, x.Bytes()))
+	}
+	proof, err := ProveDLEQBatch(G, Hs, x, newTranscript("dleq batch test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrong := append([]Point{}, Bs...)
+	wrong[1] = CommitSingle(Hs[1], GenerateRandomScalar().Bytes())
+	swapped := []Point{Bs[1], Bs[0], Bs[2]}
+
+	tests := []struct {
+		name    string
+		Hs, Bs  []Point
+		wantErr error
+	}{
+		{"诚实的证明", Hs, Bs, nil},
+		{"其中一个B用了别的x", Hs, wrong, ErrDLEQFailed},
+		{"交换两个B", Hs, swapped, ErrDLEQFailed},
+		{"少一个B", Hs, Bs[:2], ErrVectorLength},
+		{"空", nil, nil, ErrEmptySet},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := VerifyDLEQBatch(G, A, test.Hs, test.Bs, proof, newTranscript("dleq batch test"))
+			if !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil) {
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+		})
+	}
+	if _, err := ProveDLEQBatch(G, nil, x, newTranscript("dleq batch test")); !errors.Is(err, ErrEmptySet) {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+)
+
+//twisted ElGamal：承诺V = v*G + r*H不变，另外附上D = r*PK，审计者公钥PK = sk*H
+//审计者计算V - sk^-1*D = v*G，再用小步大步法求出较小的v；公众只看到V,D，V仍可以用普通的范围证明
+//ElGamalProof证明V和D使用了同一个r：知道v,r使V = v*G + r*H且D = r*PK
+
+//审计者能解密的v的最大位数，小步表有2^(bits/2)项
+const maxAuditBits = 40
+
+type ElGamalProof struct {
+	A1, A2 Point
+	sv, sr *big.Int
+}
+
+type Auditor struct {
+	PK    Point
+	sk    *big.Int
+	G     Point
+	bits  int
+	giant Point            //-m*G
+	baby  map[[33]byte]int //j*G -> j，j在[0,m)中
+	m     int
+}
+
+//生成审计者密钥并预计算小步表，可以解密[0,2^bits)中的v
+func (auditor *Auditor) New(G Point, H Point, bits int) error {
+	if bits < 1 || bits > maxAuditBits {
+		return fmt.Errorf("%w: 审计解密最多支持%d位", ErrInvalidRangeWidth, maxAuditBits)
+	}
+	auditor.sk = GenerateRandomScalar()
+	auditor.PK = CommitSingleCT(H, auditor.sk.Bytes())
+	auditor.G = G
+	auditor.bits = bits
+	auditor.m = 1 << uint((bits+1)/2)
+	auditor.baby = make(map[[33]byte]int, auditor.m)
+	step := Point{x: big.NewInt(0), y: big.NewInt(0)}
+	for j := 0; j < auditor.m; j++ {
+		var key [33]byte
+		copy(key[:], step.Bytes())
+		auditor.baby[key] = j
+		step = MultiCommit(step, G)
+	}
+	auditor.giant = CommitSingle(G, negBig(big.NewInt(int64(auditor.m))).Bytes())
+	return nil
+}
+
+//解密：v*G = V - sk^-1*D，再用小步大步法求v
+func (auditor Auditor) Decrypt(V Point, D Point) (*big.Int, error) {
+	if !IsOnCurve(V) || !IsOnCurve(D) {
+		return nil, fmt.Errorf("%w: V,D", ErrInvalidPoint)
+	}
+	skInv := inverseBigCT(auditor.sk)
+	Y := MultiCommit(V, CommitSingleCT(D, negBig(skInv).Bytes()))
+	for i := 0; i < auditor.m; i++ {
+		var key [33]byte
+		copy(key[:], Y.Bytes())
+		if j, ok := auditor.baby[key]; ok {
+			return big.NewInt(int64(i*auditor.m + j)), nil
+		}
+		Y = MultiCommit(Y, auditor.giant)
+	}
+	return nil, fmt.Errorf("%w: v不在[0,2^%d)中", ErrDecryptFailed, auditor.bits)
+}
+
+//计算D = r*PK
+func ElGamalHandle(PK Point, r *big.Int) Point {
+	return CommitSingleCT(PK, modN(r).Bytes())
+}
+
+func appendElGamalStatement(transcript *Transcript, PK Point, V Point, D Point) {
+	transcript.AppendBytes("elgamal", []byte{1})
+	transcript.AppendPoint("PK", PK)
+	transcript.AppendPoint("V", V)
+	transcript.AppendPoint("D", D)
+}
+
+//证明V = v*G + r*H和D = r*PK使用同一个r
+func ProveElGamal(G Point, H Point, PK Point, v *big.Int, r *big.Int, transcript *Transcript) ElGamalProof {
+	v, r = modN(v), modN(r)
+	appendElGamalStatement(transcript, PK, CommitCT(G, H, v.Bytes(), r.Bytes()), ElGamalHandle(PK, r))
+	kv := GenerateRandomScalar()
+	kr := GenerateRandomScalar()
+	var proof ElGamalProof
+	proof.A1 = CommitCT(G, H, kv.Bytes(), kr.Bytes())
+	proof.A2 = CommitSingleCT(PK, kr.Bytes())
+	transcript.AppendPoint("A1", proof.A1)
+	transcript.AppendPoint("A2", proof.A2)
+	c := transcript.ChallengeScalar("c")
+	proof.sv = addInP(kv, mulInP(c, v))
+	proof.sr = addInP(kr, mulInP(c, r))
+	return proof
+}
+
+//验证：sv*G + sr*H = A1 + c*V，sr*PK = A2 + c*D
+func VerifyElGamal(G Point, H Point, PK Point, V Point, D Point, proof ElGamalProof, transcript *Transcript) error {
+	for _, p := range []Point{PK, V, D} {
+		if !IsOnCurve(p) {
+			return fmt.Errorf("%w: PK,V,D", ErrInvalidPoint)
+		}
+	}
+	if !IsOnCurve(proof.A1) || !IsOnCurve(proof.A2) {
+		return fmt.Errorf("%w: %v", ErrMalformedProof, ErrInvalidPoint)
+	}
+	for _, value := range []*big.Int{proof.sv, proof.sr} {
+		if value == nil || value.Sign() < 0 || value.Cmp(curve.N) >= 0 {
+			return fmt.Errorf("%w: 标量超出范围", ErrMalformedProof)
+		}
+	}
+	appendElGamalStatement(transcript, PK, V, D)
+	transcript.AppendPoint("A1", proof.A1)
+	transcript.AppendPoint("A2", proof.A2)
+	c := transcript.ChallengeScalar("c")
+	if !IsEqual(Commit(G, H, proof.sv.Bytes(), proof.sr.Bytes()), MultiCommit(proof.A1, CommitSingle(V, c.Bytes()))) {
+		return ErrElGamalFailed
+	}
+	if !IsEqual(CommitSingle(PK, proof.sr.Bytes()), MultiCommit(proof.A2, CommitSingle(D, c.Bytes()))) {
+		return ErrElGamalFailed
+	}
+	return nil
+}
+
+//可审计的金额：承诺V、审计句柄D、同一r的证明和范围证明
+type AuditedAmount struct {
+	V, D        Point
+	Consistency ElGamalProof
+	Proof       RangeProof
+}
+
+//生成可审计的金额，两个证明共用同一个transcript
+func NewAuditedAmount(mode RangeProofMode, G Point, H Point, GVector []Point, HVector []Point, v *big.Int, r *big.Int, n int64, PK Point, transcript *Transcript) (AuditedAmount, error) {
+	if !IsOnCurve(PK) {
+		return AuditedAmount{}, fmt.Errorf("%w: 审计者公钥", ErrInvalidPoint)
+	}
+	amount := AuditedAmount{V: CommitCT(G, H, v.Bytes(), modN(r).Bytes()), D: ElGamalHandle(PK, r)}
+	amount.Consistency = ProveElGamal(G, H, PK, v, r, transcript)
+	proof, err := ProveRange(mode, G, H, GVector, HVector, v, r, n, transcript)
+	if err != nil {
+		return AuditedAmount{}, err
+	}
+	amount.Proof = proof
+	return amount, nil
+}
+
+//验证同一r的证明和范围证明，n是verifier要求的范围
+func (amount AuditedAmount) Verify(G Point, H Point, GVector []Point, HVector []Point, PK Point, n int64, transcript *Transcript) error {
+	if err := VerifyElGamal(G, H, PK, amount.V, amount.D, amount.Consistency, transcript); err != nil {
+		return err
+	}
+	return VerifyRange(G, H, GVector, HVector, amount.V, n, amount.Proof, transcript)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestAuditorDecrypt(t *testing.T) {
+	G, H := GeneratePoint(), GeneratePoint()
+	var auditor Auditor
+	if err := auditor.New(G, H, 16); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []int64{0, 1, 255, 256, 40000, 65535} {
+		r := GenerateRandomScalar()
+		V := CommitCT(G, H, big.NewInt(v).Bytes(), r.Bytes())
+		got, err := auditor.Decrypt(V, ElGamalHandle(auditor.PK, r))
+		if err != nil || got.Int64() != v {
+			t.Fatalf("v = %d: 解密得到%v, err = %v", v, got, err)
+		}
+	}
+	r := GenerateRandomScalar()
+	V := CommitCT(G, H, big.NewInt(65536).Bytes(), r.Bytes())
+	if _, err := auditor.Decrypt(V, ElGamalHandle(auditor.PK, r)); !errors.Is(err, ErrDecryptFailed) {
+		t.Fatalf("超出范围: err = %v", err)
+	}
+	if err := auditor.New(G, H, 41); !errors.Is(err, ErrInvalidRangeWidth) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestElGamalProof(t *testing.T) {
+	G, H := GeneratePoint(), GeneratePoint()
+	var auditor Auditor
+	if err := auditor.New(G, H, 8); err != nil {
+		t.Fatal(err)
+	}
+	v, r := big.NewInt(17), GenerateRandomScalar()
+	V := CommitCT(G, H, v.Bytes(), r.Bytes())
+	D := ElGamalHandle(auditor.PK, r)
+	tests := []struct {
+		name    string
+		D       Point
+		tamper  func(proof *ElGamalProof)
+		wantErr error
+	}{
+		{"同一个r", D, nil, nil},
+		{"D用了别的r", ElGamalHandle(auditor.PK, GenerateRandomScalar()), nil, ErrElGamalFailed},
+		{"篡改sr", D, func(proof *ElGamalProof) { proof.sr = addInP(proof.sr, big.NewInt(1)) }, ErrElGamalFailed},
+		{"sv超出Zp", D, func(proof *ElGamalProof) { proof.sv = big.NewInt(0).Set(curve.N) }, ErrMalformedProof},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			proof := ProveElGamal(G, H, auditor.PK, v, r, newTranscript("elgamal test"))
+			if test.tamper != nil {
+				test.tamper(&proof)
+			}
+			err := VerifyElGamal(G, H, auditor.PK, V, test.D, proof, newTranscript("elgamal test"))
+			if !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil) {
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuditedAmount(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(32)
+	var auditor Auditor
+	if err := auditor.New(G, H, 16); err != nil {
+		t.Fatal(err)
+	}
+	amount, err := NewAuditedAmount(ModeBulletproofs, G, H, GVector, HVector, big.NewInt(500), GenerateRandomScalar(), 16, auditor.PK, newTranscript("audited test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		n       int64
+		tamper  func(amount *AuditedAmount)
+		wantErr error
+	}{
+		{"诚实的金额", 16, nil, nil},
+		{"要求的范围不同", 8, nil, ErrMalformedProof},
+		{"n = 256", 256, nil, ErrInvalidRangeWidth},
+		{"替换D", 16, func(amount *AuditedAmount) { amount.D = ElGamalHandle(auditor.PK, big.NewInt(1)) }, ErrElGamalFailed},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			amount := amount
+			if test.tamper != nil {
+				test.tamper(&amount)
+			}
+			err := amount.Verify(G, H, GVector, HVector, auditor.PK, test.n, newTranscript("audited test"))
+			if !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil) {
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+		})
+	}
+	v, err := auditor.Decrypt(amount.V, amount.D)
+	if err != nil || v.Int64() != 500 {
+		t.Fatalf("v = %v, err = %v", v, err)
+	}
+}
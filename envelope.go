@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+//把承诺的打开(v,gamma)加密给接收者，ECIES方式：
+//发送者生成临时密钥e，与接收者公钥PK做ECDH得到e*PK，用HKDF-SHA256导出AES-256密钥，再用AES-GCM加密(v,gamma)
+//承诺V作为GCM的附加数据，密文不能被挪到别的承诺上
+//公钥基于secp256k1的标准基点，与承诺用的G,H无关
+
+const (
+	gcmNonceSize     = 12
+	envelopeInfo     = "RangeProof opening envelope"
+	openingPlainSize = 2 * scalarSize
+)
+
+type Envelope struct {
+	Ephemeral  Point
+	nonce      []byte
+	ciphertext []byte
+}
+
+//secp256k1的标准基点
+func basePoint() Point {
+	return Point{x: curve.Gx, y: curve.Gy}
+}
+
+//生成接收者的密钥对
+func GenerateKeyPair() (*big.Int, Point) {
+	priv := GenerateRandomScalar()
+	return priv, CommitSingleCT(basePoint(), priv.Bytes())
+}
+
+//RFC 5869的HKDF-SHA256，输出长度不超过32字节
+//go.mod要求go 1.15，标准库中还没有crypto/hkdf，这里用crypto/hmac实现
+func hkdfSHA256(secret []byte, salt []byte, info []byte, length int) []byte {
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(secret)
+	expand := hmac.New(sha256.New, extract.Sum(nil))
+	expand.Write(info)
+	expand.Write([]byte{1})
+	return expand.Sum(nil)[:length]
+}
+
+//由ECDH的共享点和临时公钥导出AES-GCM
+func envelopeCipher(shared Point, ephemeral Point) (cipher.AEAD, error) {
+	key := hkdfSHA256(shared.Bytes()[1:], ephemeral.Bytes(), []byte(envelopeInfo), 32)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+//把V = v*G + gamma*H的打开加密给公钥为recipient的接收者
+func SealOpening(recipient Point, V Point, v *big.Int, gamma *big.Int) (Envelope, error) {
+	if !IsOnCurve(recipient) {
+		return Envelope{}, fmt.Errorf("%w: 接收者公钥", ErrInvalidPoint)
+	}
+	if v.Sign() < 0 || v.BitLen() > 8*scalarSize {
+		return Envelope{}, ErrValueOutOfRange
+	}
+	e, ephemeral := GenerateKeyPair()
+	aead, err := envelopeCipher(CommitSingleCT(recipient, e.Bytes()), ephemeral)
+	e.SetInt64(0)
+	if err != nil {
+		return Envelope{}, err
+	}
+	plain := make([]byte, openingPlainSize)
+	v.FillBytes(plain[:scalarSize])
+	copy(plain[scalarSize:], ScalarBytes(gamma))
+	envelope := Envelope{Ephemeral: ephemeral, nonce: make([]byte, gcmNonceSize)}
+	if _, err := rand.Read(envelope.nonce); err != nil {
+		return Envelope{}, err
+	}
+	envelope.ciphertext = aead.Seal(nil, envelope.nonce, plain, V.Bytes())
+	for i := range plain {
+		plain[i] = 0
+	}
+	return envelope, nil
+}
+
+//接收者用私钥解密，并检查(v,gamma)确实是V的打开
+func (envelope Envelope) Open(G Point, H Point, V Point, priv *big.Int) (*big.Int, *big.Int, error) {
+	if !IsOnCurve(envelope.Ephemeral) {
+		return nil, nil, fmt.Errorf("%w: 临时公钥", ErrInvalidPoint)
+	}
+	if len(envelope.nonce) != gcmNonceSize {
+		return nil, nil, fmt.Errorf("%w: nonce长度错误", ErrMalformedProof)
+	}
+	aead, err := envelopeCipher(CommitSingleCT(envelope.Ephemeral, priv.Bytes()), envelope.Ephemeral)
+	if err != nil {
+		return nil, nil, err
+	}
+	plain, err := aead.Open(nil, envelope.nonce, envelope.ciphertext, V.Bytes())
+	if err != nil || len(plain) != openingPlainSize {
+		return nil, nil, ErrDecryptFailed
+	}
+	v := big.NewInt(0).SetBytes(plain[:scalarSize])
+	gamma := big.NewInt(0).SetBytes(plain[scalarSize:])
+	if !bytes.Equal(Commit(G, H, modN(v).Bytes(), gamma.Bytes()).Bytes(), V.Bytes()) {
+		return nil, nil, ErrOpeningMismatch
+	}
+	return v, gamma, nil
+}
+
+//带加密打开的机密金额：承诺V、范围证明和给接收者的信封
+type ConfidentialAmount struct {
+	V       Point
+	Proof   RangeProof
+	Opening Envelope
+}
+
+//生成机密金额，范围证明的验证与普通证明相同，只有接收者能打开
+func NewConfidentialAmount(mode RangeProofMode, G Point, H Point, GVector []Point, HVector []Point, v *big.Int, gamma *big.Int, n int64, recipient Point, transcript *Transcript) (ConfidentialAmount, error) {
+	proof, err := ProveRange(mode, G, H, GVector, HVector, v, gamma, n, transcript)
+	if err != nil {
+		return ConfidentialAmount{}, err
+	}
+	V := CommitCT(G, H, v.Bytes(), modN(gamma).Bytes())
+	envelope, err := SealOpening(recipient, V, v, gamma)
+	if err != nil {
+		return ConfidentialAmount{}, err
+	}
+	return ConfidentialAmount{V: V, Proof: proof, Opening: envelope}, nil
+}
+
+//验证范围证明，n是verifier要求的范围
+func (amount ConfidentialAmount) Verify(G Point, H Point, GVector []Point, HVector []Point, n int64, transcript *Transcript) error {
+	return VerifyRange(G, H, GVector, HVector, amount.V, n, amount.Proof, transcript)
+}
+
+//接收者解密并检查打开
+func (amount ConfidentialAmount) Open(G Point, H Point, priv *big.Int) (*big.Int, *big.Int, error) {
+	return amount.Opening.Open(G, H, amount.V, priv)
+}
+
+//序列化：V + 范围证明(带长度前缀) + 临时公钥 + nonce + 密文
+func (amount ConfidentialAmount) Bytes() []byte {
+	var w proofWriter
+	w.writePoint(amount.V)
+	w.writeBytes(amount.Proof.Bytes())
+	w.writePoint(amount.Opening.Ephemeral)
+	w.writeBytes(amount.Opening.nonce)
+	w.writeBytes(amount.Opening.ciphertext)
+	return w.buf
+}
+
+//从字节恢复机密金额
+func ParseConfidentialAmount(b []byte) (ConfidentialAmount, error) {
+	r := proofReader{buf: b}
+	var amount ConfidentialAmount
+	amount.V = r.readPoint()
+	proofBytes := r.readBytes()
+	amount.Opening.Ephemeral = r.readPoint()
+	amount.Opening.nonce = r.readBytes()
+	amount.Opening.ciphertext = r.readBytes()
+	if err := r.finish(); err != nil {
+		return ConfidentialAmount{}, err
+	}
+	proof, err := ParseRangeProof(proofBytes)
+	if err != nil {
+		return ConfidentialAmount{}, err
+	}
+	amount.Proof = proof
+	return amount, nil
+}
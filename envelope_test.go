@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestEnvelope(t *testing.T) {
+	G, H := GeneratePoint(), GeneratePoint()
+	priv, pub := GenerateKeyPair()
+	other, _ := GenerateKeyPair()
+	v, gamma := big.NewInt(1234), GenerateRandomScalar()
+	V := CommitCT(G, H, v.Bytes(), gamma.Bytes())
+	W := CommitCT(G, H, v.Bytes(), GenerateRandomScalar().Bytes())
+	tests := []struct {
+		name    string
+		tamper  func(envelope *Envelope)
+		V       Point
+		priv    *big.Int
+		wantErr error
+	}{
+		{"正确打开", nil, V, priv, nil},
+		{"私钥不对", nil, V, other, ErrDecryptFailed},
+		{"挪到别的承诺上", nil, W, priv, ErrDecryptFailed},
+		{"篡改密文", func(envelope *Envelope) { envelope.ciphertext[0] ^= 1 }, V, priv, ErrDecryptFailed},
+		{"nonce长度不对", func(envelope *Envelope) { envelope.nonce = envelope.nonce[1:] }, V, priv, ErrMalformedProof},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			envelope, err := SealOpening(pub, V, v, gamma)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.tamper != nil {
+				test.tamper(&envelope)
+			}
+			gotV, gotGamma, err := envelope.Open(G, H, test.V, test.priv)
+			if !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil) {
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+			if err == nil && (gotV.Cmp(v) != 0 || gotGamma.Cmp(gamma) != 0) {
+				t.Fatal("解密得到的打开不对")
+			}
+		})
+	}
+}
+
+//发送者加密了与承诺不符的打开
+func TestEnvelopeOpeningMismatch(t *testing.T) {
+	G, H := GeneratePoint(), GeneratePoint()
+	priv, pub := GenerateKeyPair()
+	gamma := GenerateRandomScalar()
+	V := CommitCT(G, H, big.NewInt(5).Bytes(), gamma.Bytes())
+	envelope, err := SealOpening(pub, V, big.NewInt(6), gamma)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := envelope.Open(G, H, V, priv); !errors.Is(err, ErrOpeningMismatch) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestConfidentialAmount(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(32)
+	priv, pub := GenerateKeyPair()
+	v := big.NewInt(99)
+	amount, err := NewConfidentialAmount(ModeBulletproofsPlus, G, H, GVector, HVector, v, GenerateRandomScalar(), 16, pub, newTranscript("amount test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseConfidentialAmount(amount.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		n       int64
+		tamper  func(amount *ConfidentialAmount)
+		wantErr error
+	}{
+		{"诚实的金额", 16, nil, nil},
+		{"要求的范围不同", 32, nil, ErrMalformedProof},
+		{"n = 256", 256, nil, ErrInvalidRangeWidth},
+		{"换了承诺", 16, func(amount *ConfidentialAmount) { amount.V = G }, ErrWIPFailed},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			amount := parsed
+			if test.tamper != nil {
+				test.tamper(&amount)
+			}
+			err := amount.Verify(G, H, GVector, HVector, test.n, newTranscript("amount test"))
+			if !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil) {
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+		})
+	}
+	got, _, err := parsed.Open(G, H, priv)
+	if err != nil || got.Cmp(v) != 0 {
+		t.Fatalf("v = %v, err = %v", got, err)
+	}
+	b := amount.Bytes()
+	if _, err := ParseConfidentialAmount(b[:len(b)-1]); err == nil {
+		t.Fatal("截断的数据解析成功")
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import "errors"
+
+//证明无效：验证等式不成立
+var (
+	ErrTxCheckFailed      = errors.New("验证t(x)失败")
+	ErrCommitPFailed      = errors.New("验证承诺P失败")
+	ErrInnerProductFailed = errors.New("验证等式相等失败")
+	ErrMembershipFailed   = errors.New("验证集合成员证明失败")
+	ErrWIPFailed          = errors.New("验证加权内积论证失败")
+	ErrOpeningFailed      = errors.New("验证打开证明失败")
+	ErrElGamalFailed      = errors.New("验证V,D使用同一r的证明失败")
+	ErrSurjectionFailed   = errors.New("验证资产满射证明失败")
+	ErrSolvencyFailed     = errors.New("验证负债和树失败")
+	ErrMerkleFailed       = errors.New("验证Merkle证明失败")
+	ErrUnbalanced         = errors.New("交易的输入与输出、手续费不平衡")
+	ErrKernelFailed       = errors.New("验证kernel签名失败")
+	ErrSignatureFailed    = errors.New("验证发行者签名失败")
+	ErrShareInvalid       = errors.New("份额与庄家的承诺不符")
+	ErrDealerFaulty       = errors.New("庄家对投诉的回应无效")
+	ErrDLEQFailed         = errors.New("验证离散对数相等证明失败")
+	ErrSigmaFailed        = errors.New("验证sigma协议失败")
+)
+
+//输入格式错误：参数或证明本身不合法，无法进行验证
+var (
+	ErrValueOutOfRange    = errors.New("v超过了要承诺的范围")
+	ErrMalformedProof     = errors.New("证明格式错误")
+	ErrInvalidPoint       = errors.New("点不在椭圆曲线上")
+	ErrGeneratorsTooShort = errors.New("G,H矢量的长度不足n位，无法提供证明")
+	ErrVectorLength       = errors.New("两个向量长度不相等")
+	ErrMissingState       = errors.New("缺少前一阶段生成的参数")
+	ErrEmptySet           = errors.New("集合为空")
+	ErrNotInSet           = errors.New("v不在集合中")
+	ErrNotPowerOfTwo      = errors.New("n不是2的幂")
+	ErrInvalidRangeWidth  = errors.New("n必须在1到128之间")
+	ErrUnknownMode        = errors.New("未知的范围证明模式")
+	ErrCommitmentMismatch = errors.New("证明不是针对给定的承诺生成的")
+	ErrMessageTooLong     = errors.New("嵌入的消息超过31字节")
+	ErrRewindFailed       = errors.New("回卷失败，密钥不对或证明不是用该密钥生成的")
+	ErrSeedTooShort       = errors.New("种子至少需要16字节")
+	ErrInvalidPath        = errors.New("导出路径格式错误")
+	ErrDecryptFailed      = errors.New("解密失败")
+	ErrOpeningMismatch    = errors.New("解密得到的v,gamma与承诺不符")
+	ErrDuplicateLiability = errors.New("客户ID重复")
+	ErrLiabilityNotFound  = errors.New("找不到该客户的负债")
+	ErrNotMimblewimble    = errors.New("参数中的H不是secp256k1的基点，不能用于Mimblewimble")
+	ErrInvalidChoice      = errors.New("选择的候选人不存在")
+	ErrDuplicateVoter     = errors.New("投票人已经投过票")
+	ErrAttributeNotFound  = errors.New("凭证中没有这个属性")
+	ErrDuplicateBidder    = errors.New("投标人已经投过标")
+	ErrInvalidThreshold   = errors.New("门限必须在1到持有者人数之间")
+	ErrNotEnoughShares    = errors.New("有效份额的个数少于门限")
+	ErrMalformedStatement = errors.New("sigma陈述格式错误")
+	ErrSigmaWitness       = errors.New("秘密不满足sigma陈述")
+	ErrTreeIndex          = errors.New("下标或大小超出了树的范围")
+	ErrNoIssuer           = errors.New("账本没有指定发行者，不能发行")
+	ErrDuplicateKernel    = errors.New("kernel重复")
+)
+
+//会话错误：协议消息的顺序不对，或会话已经结束
+var (
+	ErrOutOfOrder      = errors.New("协议步骤顺序错误")
+	ErrSessionFinished = errors.New("会话已结束，不能重复使用")
+)
+
+//账本错误：交易引用的输出状态不对
+var (
+	ErrDoubleSpend     = errors.New("输出已经被花费")
+	ErrUnknownOutput   = errors.New("账本中没有这个输出")
+	ErrDuplicateOutput = errors.New("输出的承诺与已有的输出重复")
+)
+
+//判断err是否表示证明没有通过验证，而不是输入格式错误
+func IsInvalidProof(err error) bool {
+	return errors.Is(err, ErrTxCheckFailed) ||
+		errors.Is(err, ErrCommitPFailed) ||
+		errors.Is(err, ErrInnerProductFailed) ||
+		errors.Is(err, ErrMembershipFailed) ||
+		errors.Is(err, ErrWIPFailed) ||
+		errors.Is(err, ErrOpeningFailed) ||
+		errors.Is(err, ErrElGamalFailed) ||
+		errors.Is(err, ErrSurjectionFailed) ||
+		errors.Is(err, ErrSolvencyFailed) ||
+		errors.Is(err, ErrMerkleFailed) ||
+		errors.Is(err, ErrUnbalanced) ||
+		errors.Is(err, ErrKernelFailed) ||
+		errors.Is(err, ErrSignatureFailed) ||
+		errors.Is(err, ErrShareInvalid) ||
+		errors.Is(err, ErrDealerFaulty) ||
+		errors.Is(err, ErrDLEQFailed) ||
+		errors.Is(err, ErrSigmaFailed)
+}
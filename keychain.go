@@ -0,0 +1,101 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+//由主种子确定地导出盲因子和证明用的随机数，钱包只需备份种子就能恢复所有承诺的打开
+//导出方式与BIP32的强化导出相同：I = HMAC-SHA512(chainCode, 0x00||key||index)，子密钥 = I_L + key，子链码 = I_R
+//所有层级都是强化导出，不存在可以从公开信息导出子密钥的路径
+
+//种子的最短长度
+const minSeedLength = 16
+
+type KeyChain struct {
+	key       *big.Int
+	chainCode []byte
+}
+
+//由主种子生成根节点
+func (chain *KeyChain) New(seed []byte) error {
+	if len(seed) < minSeedLength {
+		return ErrSeedTooShort
+	}
+	I := hmacSHA512([]byte("RangeProof seed"), seed)
+	key := big.NewInt(0).SetBytes(I[:32])
+	//I_L不在[1,N)中时按SLIP-10的方式重新计算
+	for key.Sign() == 0 || key.Cmp(curve.N) >= 0 {
+		I = hmacSHA512([]byte("RangeProof seed"), I)
+		key.SetBytes(I[:32])
+	}
+	chain.key = key
+	chain.chainCode = I[32:]
+	return nil
+}
+
+func hmacSHA512(key []byte, data ...[]byte) []byte {
+	mac := hmac.New(sha512.New, key)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+//导出第index个子节点
+func (chain KeyChain) Child(index uint32) KeyChain {
+	var i [4]byte
+	binary.BigEndian.PutUint32(i[:], index)
+	I := hmacSHA512(chain.chainCode, []byte{0}, ScalarBytes(chain.key), i[:])
+	for {
+		IL := big.NewInt(0).SetBytes(I[:32])
+		if IL.Cmp(curve.N) < 0 {
+			key := addInP(IL, chain.key)
+			if key.Sign() != 0 {
+				return KeyChain{key: key, chainCode: I[32:]}
+			}
+		}
+		I = hmacSHA512(chain.chainCode, []byte{1}, I[32:], i[:])
+	}
+}
+
+//按路径导出，例如"m/0/3/7"
+func (chain KeyChain) DerivePath(path string) (KeyChain, error) {
+	parts := strings.Split(path, "/")
+	if parts[0] != "m" {
+		return KeyChain{}, fmt.Errorf("%w: 路径必须以m开头", ErrInvalidPath)
+	}
+	for _, part := range parts[1:] {
+		index, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return KeyChain{}, fmt.Errorf("%w: %q", ErrInvalidPath, part)
+		}
+		chain = chain.Child(uint32(index))
+	}
+	return chain, nil
+}
+
+//该节点对应的盲因子
+func (chain KeyChain) Blinding() *big.Int {
+	return big.NewInt(0).Set(chain.key)
+}
+
+//该节点对应的回卷密钥，用于ProveRangeRewindable导出证明中的随机数
+func (chain KeyChain) RewindKey() []byte {
+	return hmacSHA512(chain.chainCode, []byte("rewind"), ScalarBytes(chain.key))[:32]
+}
+
+//重新生成路径path上对v的承诺及其盲因子
+func (chain KeyChain) CommitAt(G Point, H Point, path string, v *big.Int) (Point, *big.Int, error) {
+	child, err := chain.DerivePath(path)
+	if err != nil {
+		return Point{}, nil, err
+	}
+	gamma := child.Blinding()
+	return CommitCT(G, H, modN(v).Bytes(), gamma.Bytes()), gamma, nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func testKeyChain(t *testing.T) KeyChain {
+	var chain KeyChain
+	if err := chain.New([]byte("0123456789abcdef wallet seed")); err != nil {
+		t.Fatal(err)
+	}
+	return chain
+}
+
+func TestKeyChainDeterministic(t *testing.T) {
+	a, b := testKeyChain(t), testKeyChain(t)
+	x, err := a.DerivePath("m/0/1/2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	y := b.Child(0).Child(1).Child(2)
+	if x.Blinding().Cmp(y.Blinding()) != 0 || !bytes.Equal(x.RewindKey(), y.RewindKey()) {
+		t.Fatal("同一种子和路径导出的结果不同")
+	}
+	if a.Child(0).Blinding().Cmp(a.Child(1).Blinding()) == 0 {
+		t.Fatal("不同的子节点导出了相同的盲因子")
+	}
+	if bytes.Equal(a.Child(0).RewindKey(), a.Child(1).RewindKey()) {
+		t.Fatal("不同的子节点导出了相同的回卷密钥")
+	}
+	root, err := a.DerivePath("m")
+	if err != nil || root.Blinding().Cmp(a.Blinding()) != 0 {
+		t.Fatalf("路径m应为根节点: %v", err)
+	}
+}
+
+func TestKeyChainErrors(t *testing.T) {
+	var chain KeyChain
+	if err := chain.New(make([]byte, 15)); !errors.Is(err, ErrSeedTooShort) {
+		t.Fatalf("err = %v", err)
+	}
+	chain = testKeyChain(t)
+	for _, path := range []string{"", "0/1", "m/", "m/x", "m/-1", "m/4294967296"} {
+		if _, err := chain.DerivePath(path); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("路径%q: err = %v", path, err)
+		}
+	}
+}
+
+//从种子恢复承诺的打开，并用导出的回卷密钥恢复v
+func TestKeyChainRecovery(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(16)
+	chain := testKeyChain(t)
+	v := big.NewInt(4321)
+	V, gamma, err := chain.CommitAt(G, H, "m/7/0", v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, _ := chain.DerivePath("m/7/0")
+	proof, err := ProveRangeRewindable(G, H, GVector, HVector, v, gamma, 16, child.RewindKey(), nil, newTranscript("keychain test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := testKeyChain(t)
+	W, _, err := restored.CommitAt(G, H, "m/7/0", v)
+	if err != nil || !IsEqual(V, W) {
+		t.Fatalf("从种子恢复的承诺不同: %v", err)
+	}
+	child, _ = restored.DerivePath("m/7/0")
+	got, _, _, err := RewindRange(G, H, V, proof, child.RewindKey(), newTranscript("keychain test"))
+	if err != nil || got.Cmp(v) != 0 {
+		t.Fatalf("v = %v, err = %v", got, err)
+	}
+	child, _ = restored.DerivePath("m/7/1")
+	if _, _, _, err := RewindRange(G, H, V, proof, child.RewindKey(), newTranscript("keychain test")); !errors.Is(err, ErrRewindFailed) {
+		t.Fatalf("其他路径的密钥: err = %v", err)
+	}
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/decred/dcrd/dcrec/secp256k1/v3"
+	"github.com/decred/dcrd/dcrec/secp256k1/v3/schnorr"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"sort"
+)
+
+//机密账本：保存未花费输出的承诺，接受由输入、输出、手续费、范围证明和平衡证明组成的交易
+//平衡关系：Σ输入 - Σ输出 = fee*G + excess*H，平衡证明就是对公开值fee的打开证明，说明差值中没有凭空产生的金额
+//每个输出带有[0,2^n)的范围证明，防止用负数输出制造金额
+//新的金额只能由发行者签名发行；发行的金额是隐藏的，账本无法检查总供应量，总量的正确性只能依赖发行者
+//已花费的承诺全部保留，用来拒绝双花和重复的输出，这个集合只增不减，会随交易数量无限增长
+//账本可以只在内存中，也可以指定文件，每次修改后写回磁盘
+
+type LedgerParams struct {
+	Mode             RangeProofMode
+	G, H             Point
+	GVector, HVector []Point
+	N                int64
+	Issuer           Point //发行者的公钥，为空时不能发行
+}
+
+type Output struct {
+	V     Point
+	Proof RangeProof
+}
+
+//输出的打开，只有所有者知道
+type OutputOpening struct {
+	Value *big.Int
+	Gamma *big.Int
+}
+
+type Transaction struct {
+	Inputs  []Point
+	Outputs []Output
+	Fee     *big.Int
+	Balance OpeningProof
+}
+
+type ledgerEntry struct {
+	output Output
+	seq    int64
+}
+
+type Ledger struct {
+	params  LedgerParams
+	path    string
+	unspent map[string]ledgerEntry
+	spent   map[string]bool
+	seq     int64
+}
+
+func outputKey(V Point) string {
+	return string(V.Bytes())
+}
+
+func outputTranscript() *Transcript {
+	var t Transcript
+	t.New("ledger output")
+	return &t
+}
+
+//平衡证明的transcript覆盖交易的全部输入、输出和手续费，证明不能挪到别的交易上
+func balanceTranscript(inputs []Point, outputs []Output, fee *big.Int) *Transcript {
+	var t Transcript
+	t.New("ledger balance")
+	t.AppendInt("inputs", int64(len(inputs)))
+	for _, input := range inputs {
+		t.AppendPoint("input", input)
+	}
+	t.AppendInt("outputs", int64(len(outputs)))
+	for _, output := range outputs {
+		t.AppendPoint("output", output.V)
+	}
+	t.AppendScalar("fee", fee)
+	return &t
+}
+
+//生成带范围证明的输出
+func (params LedgerParams) NewOutput(opening OutputOpening) (Output, error) {
+	proof, err := ProveRange(params.Mode, params.G, params.H, params.GVector, params.HVector, opening.Value, opening.Gamma, params.N, outputTranscript())
+	if err != nil {
+		return Output{}, err
+	}
+	return Output{V: CommitCT(params.G, params.H, opening.Value.Bytes(), modN(opening.Gamma).Bytes()), Proof: proof}, nil
+}
+
+//由输入和输出的打开构造交易，要求Σ输入 = Σ输出 + fee
+func (params LedgerParams) NewTransaction(inputs []OutputOpening, outputs []OutputOpening, fee *big.Int) (Transaction, error) {
+	if fee.Sign() < 0 {
+		return Transaction{}, ErrValueOutOfRange
+	}
+	tx := Transaction{Fee: big.NewInt(0).Set(fee)}
+	sum := big.NewInt(0).Neg(fee)
+	excess := big.NewInt(0)
+	for _, input := range inputs {
+		tx.Inputs = append(tx.Inputs, CommitCT(params.G, params.H, input.Value.Bytes(), modN(input.Gamma).Bytes()))
+		sum.Add(sum, input.Value)
+		excess = addInP(excess, modN(input.Gamma))
+	}
+	for _, opening := range outputs {
+		output, err := params.NewOutput(opening)
+		if err != nil {
+			return Transaction{}, err
+		}
+		tx.Outputs = append(tx.Outputs, output)
+		sum.Sub(sum, opening.Value)
+		excess = subInP(excess, modN(opening.Gamma))
+	}
+	if sum.Sign() != 0 {
+		return Transaction{}, ErrUnbalanced
+	}
+	tx.Balance = ProveOpening(params.G, params.H, fee, excess, balanceTranscript(tx.Inputs, tx.Outputs, fee))
+	return tx, nil
+}
+
+//创建账本，path为空时只在内存中；否则从文件加载（文件不存在时为空账本），之后每次修改都写回文件
+func (ledger *Ledger) New(params LedgerParams, path string) error {
+	ledger.params = params
+	ledger.path = path
+	ledger.unspent = make(map[string]ledgerEntry)
+	ledger.spent = make(map[string]bool)
+	ledger.seq = 0
+	if path == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return ledger.decode(data)
+}
+
+//发行签名的消息
+func issueMessage(V Point) []byte {
+	h := sha256.New()
+	writeWithLength(h.Write, []byte("ledger issue"))
+	writeWithLength(h.Write, V.Bytes())
+	return h.Sum(nil)
+}
+
+//发行者用私钥对要发行的输出签名，密钥对可以由GenerateKeyPair生成
+func SignIssue(issuerKey *big.Int, output Output) (*schnorr.Signature, error) {
+	priv := secp256k1.PrivKeyFromBytes(ScalarBytes(issuerKey))
+	defer priv.Zero()
+	return schnorr.Sign(priv, issueMessage(output.V))
+}
+
+//发行新的输出（例如创世分配），检查发行者的签名和范围证明，不检查平衡
+//同一个承诺不能发行两次，所以签名不能被重放
+func (ledger *Ledger) Issue(output Output, signature *schnorr.Signature) error {
+	if ledger.params.Issuer.x == nil {
+		return ErrNoIssuer
+	}
+	if signature == nil {
+		return fmt.Errorf("%w: 缺少发行者签名", ErrMalformedProof)
+	}
+	pub, err := secp256k1.ParsePubKey(ledger.params.Issuer.Bytes())
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPoint, err)
+	}
+	if !IsOnCurve(output.V) {
+		return fmt.Errorf("%w: 承诺V", ErrInvalidPoint)
+	}
+	if !signature.Verify(issueMessage(output.V), pub) {
+		return ErrSignatureFailed
+	}
+	if err := ledger.checkOutput(output, map[string]bool{}); err != nil {
+		return err
+	}
+	ledger.addOutput(output)
+	return ledger.persist(func() {
+		delete(ledger.unspent, outputKey(output.V))
+	})
+}
+
+//检查输出：承诺不能与已有的或本交易中的其他输出重复，范围证明必须有效
+func (ledger *Ledger) checkOutput(output Output, seen map[string]bool) error {
+	key := outputKey(output.V)
+	if _, ok := ledger.unspent[key]; ok || ledger.spent[key] || seen[key] {
+		return ErrDuplicateOutput
+	}
+	seen[key] = true
+	params := ledger.params
+	return VerifyRange(params.G, params.H, params.GVector, params.HVector, output.V, params.N, output.Proof, outputTranscript())
+}
+
+func (ledger *Ledger) addOutput(output Output) {
+	ledger.unspent[outputKey(output.V)] = ledgerEntry{output: output, seq: ledger.seq}
+	ledger.seq++
+}
+
+//验证交易，全部通过后花费输入、加入输出
+func (ledger *Ledger) Apply(tx Transaction) error {
+	if err := ledger.Validate(tx); err != nil {
+		return err
+	}
+	return ledger.spend(tx.Inputs, tx.Outputs)
+}
+
+//花费输入、加入输出并写回文件，调用前必须已经验证过
+func (ledger *Ledger) spend(inputs []Point, outputs []Output) error {
+	removed := make(map[string]ledgerEntry)
+	for _, input := range inputs {
+		key := outputKey(input)
+		removed[key] = ledger.unspent[key]
+		delete(ledger.unspent, key)
+		ledger.spent[key] = true
+	}
+	for _, output := range outputs {
+		ledger.addOutput(output)
+	}
+	return ledger.persist(func() {
+		for _, output := range outputs {
+			delete(ledger.unspent, outputKey(output.V))
+		}
+		for key, entry := range removed {
+			ledger.unspent[key] = entry
+			delete(ledger.spent, key)
+		}
+	})
+}
+
+//验证交易但不修改账本
+func (ledger *Ledger) Validate(tx Transaction) error {
+	if len(tx.Inputs) == 0 || len(tx.Outputs) == 0 {
+		return fmt.Errorf("%w: 交易没有输入或输出", ErrMalformedProof)
+	}
+	if tx.Fee == nil || tx.Fee.Sign() < 0 || tx.Fee.Cmp(curve.N) >= 0 {
+		return ErrValueOutOfRange
+	}
+	inputs := make(map[string]bool)
+	for _, input := range tx.Inputs {
+		key := outputKey(input)
+		if inputs[key] || ledger.spent[key] {
+			return ErrDoubleSpend
+		}
+		if _, ok := ledger.unspent[key]; !ok {
+			return ErrUnknownOutput
+		}
+		inputs[key] = true
+	}
+	seen := make(map[string]bool)
+	for _, output := range tx.Outputs {
+		if err := ledger.checkOutput(output, seen); err != nil {
+			return err
+		}
+	}
+
+	//Σ输入 - Σ输出 = fee*G + excess*H
+	minusOne := negBig(big.NewInt(1)).Bytes()
+	sum := Point{x: big.NewInt(0), y: big.NewInt(0)}
+	for _, input := range tx.Inputs {
+		sum = MultiCommit(sum, input)
+	}
+	for _, output := range tx.Outputs {
+		sum = MultiCommit(sum, CommitSingle(output.V, minusOne))
+	}
+	err := VerifyOpening(ledger.params.G, ledger.params.H, sum, tx.Fee, tx.Balance, balanceTranscript(tx.Inputs, tx.Outputs, tx.Fee))
+	if IsInvalidProof(err) {
+		return fmt.Errorf("%w: %v", ErrUnbalanced, err)
+	}
+	return err
+}
+
+//查询未花费的输出
+func (ledger *Ledger) Output(V Point) (Output, error) {
+	entry, ok := ledger.unspent[outputKey(V)]
+	if !ok {
+		if ledger.spent[outputKey(V)] {
+			return Output{}, ErrDoubleSpend
+		}
+		return Output{}, ErrUnknownOutput
+	}
+	return entry.output, nil
+}
+
+//判断输出是否已经被花费
+func (ledger *Ledger) IsSpent(V Point) bool {
+	return ledger.spent[outputKey(V)]
+}
+
+//按加入账本的顺序返回所有未花费的输出
+func (ledger *Ledger) Outputs() []Output {
+	entries := make([]ledgerEntry, 0, len(ledger.unspent))
+	for _, entry := range ledger.unspent {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].seq < entries[j].seq
+	})
+	outputs := make([]Output, len(entries))
+	for i, entry := range entries {
+		outputs[i] = entry.output
+	}
+	return outputs
+}
+
+//未花费输出的个数
+func (ledger *Ledger) Size() int {
+	return len(ledger.unspent)
+}
+
+//把账本写回文件，写入失败时调用rollback撤销内存中的修改
+//先写临时文件再改名，避免中途失败留下不完整的文件
+func (ledger *Ledger) persist(rollback func()) error {
+	if ledger.path == "" {
+		return nil
+	}
+	tmp := ledger.path + ".tmp"
+	err := ioutil.WriteFile(tmp, ledger.encode(), 0600)
+	if err == nil {
+		err = os.Rename(tmp, ledger.path)
+	}
+	if err != nil {
+		rollback()
+	}
+	return err
+}
+
+//序列化：未花费输出(按顺序，每个为V + 带长度前缀的范围证明) + 已花费的承诺
+func (ledger *Ledger) encode() []byte {
+	var w proofWriter
+	outputs := ledger.Outputs()
+	w.writeInt(int64(len(outputs)))
+	for _, output := range outputs {
+		w.writePoint(output.V)
+		w.writeBytes(output.Proof.Bytes())
+	}
+	var spent []Point
+	for key := range ledger.spent {
+		p, _ := PointFromBytes([]byte(key))
+		spent = append(spent, p)
+	}
+	sort.Slice(spent, func(i, j int) bool {
+		return string(spent[i].Bytes()) < string(spent[j].Bytes())
+	})
+	w.writePoints(spent)
+	return w.buf
+}
+
+func (ledger *Ledger) decode(data []byte) error {
+	r := proofReader{buf: data}
+	for i := r.readLength(pointSize); i > 0; i-- {
+		V := r.readPoint()
+		proofBytes := r.readBytes()
+		if r.err != nil {
+			break
+		}
+		proof, err := ParseRangeProof(proofBytes)
+		if err != nil {
+			return err
+		}
+		ledger.addOutput(Output{V: V, Proof: proof})
+	}
+	for _, p := range r.readPoints() {
+		ledger.spent[outputKey(p)] = true
+	}
+	return r.finish()
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"errors"
+	"github.com/decred/dcrd/dcrec/secp256k1/v3/schnorr"
+	"math/big"
+	"path/filepath"
+	"testing"
+)
+
+//测试用的账本参数和发行者私钥
+func testLedgerParams() (LedgerParams, *big.Int) {
+	G, H, GVector, HVector := testGenerators(16)
+	issuerKey, issuer := GenerateKeyPair()
+	return LedgerParams{Mode: ModeBulletproofs, G: G, H: H, GVector: GVector, HVector: HVector, N: 16, Issuer: issuer}, issuerKey
+}
+
+//发行一个金额为v的输出，返回它的打开
+func issueTestOutput(t *testing.T, ledger *Ledger, params LedgerParams, issuerKey *big.Int, v int64) OutputOpening {
+	opening := OutputOpening{Value: big.NewInt(v), Gamma: GenerateRandomScalar()}
+	output, err := params.NewOutput(opening)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := SignIssue(issuerKey, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ledger.Issue(output, signature); err != nil {
+		t.Fatal(err)
+	}
+	return opening
+}
+
+func TestLedgerIssue(t *testing.T) {
+	params, issuerKey := testLedgerParams()
+	var ledger Ledger
+	if err := ledger.New(params, ""); err != nil {
+		t.Fatal(err)
+	}
+	output, err := params.NewOutput(OutputOpening{Value: big.NewInt(100), Gamma: GenerateRandomScalar()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := params.NewOutput(OutputOpening{Value: big.NewInt(100), Gamma: GenerateRandomScalar()})
+	narrow := params
+	narrow.N = 8
+	wide, err := narrow.NewOutput(OutputOpening{Value: big.NewInt(100), Gamma: GenerateRandomScalar()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, _ := GenerateKeyPair()
+	sign := func(key *big.Int, output Output) *schnorr.Signature {
+		signature, err := SignIssue(key, output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signature
+	}
+
+	//按顺序执行，后面的用例依赖前面的发行结果
+	tests := []struct {
+		name      string
+		output    Output
+		signature *schnorr.Signature
+		wantErr   error
+	}{
+		{"别人的签名", output, sign(otherKey, output), ErrSignatureFailed},
+		{"签名属于别的输出", other, sign(issuerKey, output), ErrSignatureFailed},
+		{"缺少签名", output, nil, ErrMalformedProof},
+		{"范围不是约定的n", wide, sign(issuerKey, wide), ErrMalformedProof},
+		{"发行者签名", output, sign(issuerKey, output), nil},
+		{"重放签名", output, sign(issuerKey, output), ErrDuplicateOutput},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ledger.Issue(test.output, test.signature)
+			if !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil) {
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+		})
+	}
+	if ledger.Size() != 1 {
+		t.Fatalf("账本中有%d个输出", ledger.Size())
+	}
+
+	//没有指定发行者的账本不能发行
+	params.Issuer = Point{}
+	var closed Ledger
+	if err := closed.New(params, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := closed.Issue(other, sign(issuerKey, other)); !errors.Is(err, ErrNoIssuer) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestLedgerApply(t *testing.T) {
+	params, issuerKey := testLedgerParams()
+	var ledger Ledger
+	if err := ledger.New(params, ""); err != nil {
+		t.Fatal(err)
+	}
+	input := issueTestOutput(t, &ledger, params, issuerKey, 100)
+	outputs := []OutputOpening{
+		{Value: big.NewInt(60), Gamma: GenerateRandomScalar()},
+		{Value: big.NewInt(35), Gamma: GenerateRandomScalar()},
+	}
+	tx, err := params.NewTransaction([]OutputOpening{input}, outputs, big.NewInt(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := params.NewTransaction([]OutputOpening{input}, outputs, big.NewInt(6)); !errors.Is(err, ErrUnbalanced) {
+		t.Fatalf("err = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		tamper  func(tx *Transaction)
+		wantErr error
+	}{
+		{"篡改手续费", func(tx *Transaction) { tx.Fee = big.NewInt(4) }, ErrUnbalanced},
+		{"删掉一个输出", func(tx *Transaction) { tx.Outputs = tx.Outputs[:1] }, ErrUnbalanced},
+		{"重复的输入", func(tx *Transaction) { tx.Inputs = append(tx.Inputs, tx.Inputs[0]) }, ErrDoubleSpend},
+		{"未知的输入", func(tx *Transaction) { tx.Inputs = []Point{GeneratePoint()} }, ErrUnknownOutput},
+		{"重复的输出", func(tx *Transaction) { tx.Outputs[1] = tx.Outputs[0] }, ErrDuplicateOutput},
+		{"负的手续费", func(tx *Transaction) { tx.Fee = big.NewInt(-1) }, ErrValueOutOfRange},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tampered := tx
+			tampered.Inputs = append([]Point{}, tx.Inputs...)
+			tampered.Outputs = append([]Output{}, tx.Outputs...)
+			test.tamper(&tampered)
+			if err := ledger.Apply(tampered); !errors.Is(err, test.wantErr) {
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+		})
+	}
+
+	if err := ledger.Apply(tx); err != nil {
+		t.Fatal(err)
+	}
+	if ledger.Size() != 2 || !ledger.IsSpent(tx.Inputs[0]) {
+		t.Fatal("交易没有正确地修改账本")
+	}
+	if err := ledger.Apply(tx); !errors.Is(err, ErrDoubleSpend) {
+		t.Fatalf("重复应用交易: err = %v", err)
+	}
+}
+
+func TestLedgerPersist(t *testing.T) {
+	params, issuerKey := testLedgerParams()
+	path := filepath.Join(t.TempDir(), "ledger")
+	var ledger Ledger
+	if err := ledger.New(params, path); err != nil {
+		t.Fatal(err)
+	}
+	input := issueTestOutput(t, &ledger, params, issuerKey, 10)
+	issueTestOutput(t, &ledger, params, issuerKey, 20)
+	tx, err := params.NewTransaction([]OutputOpening{input}, []OutputOpening{{Value: big.NewInt(10), Gamma: GenerateRandomScalar()}}, big.NewInt(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ledger.Apply(tx); err != nil {
+		t.Fatal(err)
+	}
+
+	var loaded Ledger
+	if err := loaded.New(params, path); err != nil {
+		t.Fatal(err)
+	}
+	want, got := ledger.Outputs(), loaded.Outputs()
+	if len(got) != len(want) {
+		t.Fatalf("加载了%d个输出，应为%d个", len(got), len(want))
+	}
+	for i := range want {
+		if !IsEqual(got[i].V, want[i].V) {
+			t.Fatalf("第%d个输出不同", i)
+		}
+	}
+	if !loaded.IsSpent(tx.Inputs[0]) {
+		t.Fatal("已花费的承诺没有被保存")
+	}
+}
@@ -6,20 +6,33 @@ import (
 	"math/big"
 )
 
-var prover Prover
-var verifier Verifier
+var proverSession ProverSession
+var verifierSession VerifierSession
+var proof ProverZKP
 
 func main(){
 
-	setup(100,10)
-	zkpConstruct()
-	zkp()
-	isInRange := zkpVerify()
-	fmt.Println(isInRange)
+	if err := setup(big.NewInt(100),10); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := zkpConstruct(); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := zkp(); err != nil {
+		fmt.Println(err)
+		return
+	}
+	err := zkpVerify()
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(err == nil)
 	//test()
 }
 
-func setup(v int64,n int64) {
+func setup(v *big.Int,n int64) error {
 
 	curve = secp256k1.S256()
 	g := GeneratePoint()
@@ -27,45 +40,66 @@ func setup(v int64,n int64) {
 	gVector := GenerateMultiPoint(n)
 	hVector := GenerateMultiPoint(n)
 
-	//创建一个prover对象
-	err := prover.New(g,h,gVector,hVector,v,n,*curve)
+	//创建一个prover会话
+	err := proverSession.New(g,h,gVector,hVector,v,n,*curve)
 	if err!=nil {
-		fmt.Println(err)
-		return
+		return err
+	}
+	//创建一个verifier会话
+	err = verifierSession.New(g,h,gVector,hVector,n,*curve)
+	if err!=nil {
+		return err
 	}
-	//创建一个verifier对象
-	verifier.New(g,h,gVector,hVector,n,*curve)
 
 	//获取A,S，将两个承诺传递给verifier
-	A,S := prover.GetAS()
-	verifier.GetAS(A,S)
+	A,S,err := proverSession.CommitAS()
+	if err!=nil {
+		return err
+	}
+	err = verifierSession.ReceiveAS(A,S)
+	if err!=nil {
+		return err
+	}
 
 	//在verifier接收到A,S后，将y,z传递给prover
-	verifier.GenerateYZ()
-	prover.y = verifier.y
-	prover.z = verifier.z
-
+	y,z,err := verifierSession.ChallengeYZ()
+	if err!=nil {
+		return err
+	}
+	return proverSession.ReceiveYZ(y,z)
 }
 
-func zkpConstruct(){
+func zkpConstruct() error {
 
 	//获取T1,T2，将两个承诺传递给verifier
-	T1,T2 := prover.GetT()
-	verifier.GetT(T1,T2)
+	T1,T2,err := proverSession.CommitT()
+	if err!=nil {
+		return err
+	}
+	err = verifierSession.ReceiveT(T1,T2)
+	if err!=nil {
+		return err
+	}
 
 	//将随机数x传递给prover
-	verifier.GenerateX()
-	prover.x = verifier.x
-
+	x,err := verifierSession.ChallengeX()
+	if err!=nil {
+		return err
+	}
+	return proverSession.ReceiveX(x)
 }
 
-func zkp(){
-	proverZKP := prover.GetProverZKP()
-	verifier.proverZKP = proverZKP
+func zkp() error {
+	proverZKP,err := proverSession.Prove()
+	if err!=nil {
+		return err
+	}
+	proof = proverZKP
+	return nil
 }
 
-func zkpVerify() bool{
-	return verifier.VerifyZKP()
+func zkpVerify() error {
+	return verifierSession.Verify(proof)
 }
 
 func test() {
@@ -76,13 +110,13 @@ func test() {
 	x1 := big.NewInt(20)
 	x2 := negBig(big.NewInt(31))
 
-	commit2 := CommitSingle(prover.H,x0.Bytes())
-	commit3 := Commit(prover.H,prover.H,x1.Bytes(),x2.Bytes())
+	commit2 := CommitSingle(proverSession.prover.H,x0.Bytes())
+	commit3 := Commit(proverSession.prover.H,proverSession.prover.H,x1.Bytes(),x2.Bytes())
 
 
-	V := Commit(prover.G,prover.H,big.NewInt(prover.v).Bytes(),big.NewInt(int64(prover.gamma)).Bytes())
-	commit0 := Commit(prover.G,prover.H,tx.Bytes(),taux.Bytes())
-	commit1:= Commit(V,prover.G,big.NewInt(1).Bytes(),delta.Bytes())
+	V := Commit(proverSession.prover.G,proverSession.prover.H,proverSession.prover.v.Bytes(),big.NewInt(int64(proverSession.prover.gamma)).Bytes())
+	commit0 := Commit(proverSession.prover.G,proverSession.prover.H,tx.Bytes(),taux.Bytes())
+	commit1:= Commit(V,proverSession.prover.G,big.NewInt(1).Bytes(),delta.Bytes())
 
 	fmt.Println(IsEqual(commit0,commit1))
 	fmt.Println(IsEqual(commit2,commit3))
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"github.com/decred/dcrd/dcrec/secp256k1/v3"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	curve = secp256k1.S256()
+	os.Exit(m.Run())
+}
+
+//测试用的生成元
+func testGenerators(n int64) (G Point, H Point, GVector []Point, HVector []Point) {
+	return GeneratePoint(), GeneratePoint(), GenerateMultiPoint(n), GenerateMultiPoint(n)
+}
+
+//用label初始化的transcript，prover和verifier各用一个
+func newTranscript(label string) *Transcript {
+	var transcript Transcript
+	transcript.New(label)
+	return &transcript
+}
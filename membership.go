@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+)
+
+//集合成员证明：证明承诺V = v*G + gamma*H中的v属于公开的集合{s_0,...,s_(N-1)}
+//对每个s_i，C_i = V - s_i*G，当s_i = v时C_i = gamma*H是对0的承诺
+//用Groth-Kohlweiss的one-out-of-many证明说明知道某个C_i对H的离散对数，而不泄露是哪一个
+//集合补齐到N = 2^m，证明包含4m个点和3m+1个数，大小为O(log N)
+
+type MembershipProof struct {
+	cl, ca, cb, cd []Point
+	f, za, zb      []*big.Int
+	zd             *big.Int
+}
+
+//把集合补齐到2的幂，重复最后一个元素，返回补齐后的集合和m
+//元素都映射到[0,N)，mulInP等运算会丢掉负数的符号
+func padSet(set []*big.Int) ([]*big.Int, int) {
+	m := 1
+	for 1<<uint(m) < len(set) {
+		m++
+	}
+	var padded []*big.Int
+	for _, value := range set {
+		padded = append(padded, modN(value))
+	}
+	for len(padded) < 1<<uint(m) {
+		padded = append(padded, padded[len(set)-1])
+	}
+	return padded, m
+}
+
+//计算多项式a(x)*(c1*x+c0)，多项式按x的升幂存储
+func mulLinear(a []*big.Int, c1 *big.Int, c0 *big.Int) []*big.Int {
+	c := make([]*big.Int, len(a)+1)
+	for i := range c {
+		c[i] = big.NewInt(0)
+	}
+	for i, value := range a {
+		c[i] = addInP(c[i], mulInP(value, c0))
+		c[i+1] = addInP(c[i+1], mulInP(value, c1))
+	}
+	return c
+}
+
+func appendMembershipStatement(transcript *Transcript, V Point, set []*big.Int, m int) {
+	transcript.AppendInt("m", int64(m))
+	for _, value := range set {
+		transcript.AppendScalar("s", value)
+	}
+	transcript.AppendPoint("V", V)
+}
+
+//生成集合成员证明
+func ProveMembership(G Point, H Point, v *big.Int, gamma *big.Int, set []*big.Int, transcript *Transcript) (MembershipProof, error) {
+	if len(set) == 0 {
+		return MembershipProof{}, ErrEmptySet
+	}
+	padded, m := padSet(set)
+	index := -1
+	for i, value := range padded[:len(set)] {
+		if value.Cmp(modN(v)) == 0 {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return MembershipProof{}, ErrNotInSet
+	}
+
+	//v为负数时承诺的也是v mod N，与集合中的元素一致
+	V := CommitCT(G, H, modN(v).Bytes(), modN(gamma).Bytes())
+	appendMembershipStatement(transcript, V, padded, m)
+
+	var proof MembershipProof
+	var bits, r, a, s, t, rho []*big.Int
+	for j := 0; j < m; j++ {
+		bits = append(bits, big.NewInt(int64((index>>uint(j))&1)))
+		r = append(r, GenerateRandomScalar())
+		a = append(a, GenerateRandomScalar())
+		s = append(s, GenerateRandomScalar())
+		t = append(t, GenerateRandomScalar())
+		rho = append(rho, GenerateRandomScalar())
+
+		proof.cl = append(proof.cl, CommitCT(G, H, bits[j].Bytes(), r[j].Bytes()))
+		proof.ca = append(proof.ca, CommitCT(G, H, a[j].Bytes(), s[j].Bytes()))
+		proof.cb = append(proof.cb, CommitCT(G, H, mulInP(bits[j], a[j]).Bytes(), t[j].Bytes()))
+	}
+
+	//p_i(x) = Π_j f_(j,i_j)(x)，其中f_(j,1)(x) = l_j*x + a_j，f_(j,0)(x) = (1-l_j)*x - a_j
+	//Q_k = Σ_i p_(i,k)*s_i，由于Σ_i p_i(x) = x^m，cd_k = -Q_k*G + rho_k*H
+	Q := make([]*big.Int, m)
+	for k := range Q {
+		Q[k] = big.NewInt(0)
+	}
+	for i, value := range padded {
+		p := []*big.Int{big.NewInt(1)}
+		for j := 0; j < m; j++ {
+			if (i>>uint(j))&1 == 1 {
+				p = mulLinear(p, bits[j], a[j])
+			} else {
+				p = mulLinear(p, subInP(big.NewInt(1), bits[j]), negBig(a[j]))
+			}
+		}
+		for k := 0; k < m; k++ {
+			Q[k] = addInP(Q[k], mulInP(p[k], value))
+		}
+	}
+	for k := 0; k < m; k++ {
+		proof.cd = append(proof.cd, CommitCT(G, H, negBig(Q[k]).Bytes(), rho[k].Bytes()))
+	}
+	appendMembershipCommitments(transcript, proof)
+
+	x := transcript.ChallengeScalar("x")
+	xn := GenerateYBig(x, int64(m)+1)
+	for j := 0; j < m; j++ {
+		f := addInP(mulInP(bits[j], x), a[j])
+		proof.f = append(proof.f, f)
+		proof.za = append(proof.za, addInP(mulInP(r[j], x), s[j]))
+		proof.zb = append(proof.zb, addInP(mulInP(r[j], subInP(x, f)), t[j]))
+	}
+	zd := mulInP(gamma, xn[m])
+	for k := 0; k < m; k++ {
+		zd = subInP(zd, mulInP(rho[k], xn[k]))
+	}
+	proof.zd = zd
+	return proof, nil
+}
+
+func appendMembershipCommitments(transcript *Transcript, proof MembershipProof) {
+	for j := range proof.cl {
+		transcript.AppendPoint("cl", proof.cl[j])
+		transcript.AppendPoint("ca", proof.ca[j])
+		transcript.AppendPoint("cb", proof.cb[j])
+		transcript.AppendPoint("cd", proof.cd[j])
+	}
+}
+
+//检查证明的格式
+func (proof MembershipProof) check(m int) error {
+	if len(proof.cl) != m || len(proof.ca) != m || len(proof.cb) != m || len(proof.cd) != m ||
+		len(proof.f) != m || len(proof.za) != m || len(proof.zb) != m {
+		return fmt.Errorf("%w: 证明的长度与集合大小不符", ErrMalformedProof)
+	}
+	for _, p := range append(append(append(append([]Point{}, proof.cl...), proof.ca...), proof.cb...), proof.cd...) {
+		if !IsOnCurve(p) {
+			return fmt.Errorf("%w: %v", ErrMalformedProof, ErrInvalidPoint)
+		}
+	}
+	for _, value := range append(append(append([]*big.Int{proof.zd}, proof.f...), proof.za...), proof.zb...) {
+		if value == nil || value.Sign() < 0 || value.Cmp(curve.N) >= 0 {
+			return fmt.Errorf("%w: 标量超出范围", ErrMalformedProof)
+		}
+	}
+	return nil
+}
+
+//验证集合成员证明
+func VerifyMembership(G Point, H Point, V Point, set []*big.Int, proof MembershipProof, transcript *Transcript) error {
+	if len(set) == 0 {
+		return ErrEmptySet
+	}
+	padded, m := padSet(set)
+	if err := proof.check(m); err != nil {
+		return err
+	}
+	if !IsOnCurve(V) {
+		return fmt.Errorf("%w: 承诺V", ErrInvalidPoint)
+	}
+	appendMembershipStatement(transcript, V, padded, m)
+	appendMembershipCommitments(transcript, proof)
+	x := transcript.ChallengeScalar("x")
+	xn := GenerateYBig(x, int64(m)+1)
+
+	//x*cl_j + ca_j = f_j*G + za_j*H，(x-f_j)*cl_j + cb_j = zb_j*H，保证l_j是0或1
+	for j := 0; j < m; j++ {
+		left := MultiCommit(CommitSingle(proof.cl[j], x.Bytes()), proof.ca[j])
+		if !IsEqual(left, Commit(G, H, proof.f[j].Bytes(), proof.za[j].Bytes())) {
+			return ErrMembershipFailed
+		}
+		left = MultiCommit(CommitSingle(proof.cl[j], subInP(x, proof.f[j]).Bytes()), proof.cb[j])
+		if !IsEqual(left, CommitSingle(H, proof.zb[j].Bytes())) {
+			return ErrMembershipFailed
+		}
+	}
+
+	//Σ_i p_i(x)*C_i - Σ_k x^k*cd_k = zd*H，其中Σ_i p_i(x)*C_i = x^m*V - (Σ_i p_i(x)*s_i)*G
+	sum := big.NewInt(0)
+	for i, value := range padded {
+		p := big.NewInt(1)
+		for j := 0; j < m; j++ {
+			if (i>>uint(j))&1 == 1 {
+				p = mulInP(p, proof.f[j])
+			} else {
+				p = mulInP(p, subInP(x, proof.f[j]))
+			}
+		}
+		sum = addInP(sum, mulInP(p, value))
+	}
+	left := Commit(V, G, xn[m].Bytes(), negBig(sum).Bytes())
+	for k := 0; k < m; k++ {
+		left = MultiCommit(left, CommitSingle(proof.cd[k], negBig(xn[k]).Bytes()))
+	}
+	if !IsEqual(left, CommitSingle(H, proof.zd.Bytes())) {
+		return ErrMembershipFailed
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func bigs(values ...int64) []*big.Int {
+	var result []*big.Int
+	for _, value := range values {
+		result = append(result, big.NewInt(value))
+	}
+	return result
+}
+
+func TestMembership(t *testing.T) {
+	tiers := bigs(3, 7, 11, 19, 42)
+	tests := []struct {
+		name      string
+		v         int64
+		set       []*big.Int
+		verifySet []*big.Int
+		tamper    func(proof *MembershipProof)
+		valid     bool
+	}{
+		{"第一个元素", 3, tiers, tiers, nil, true},
+		{"最后一个元素", 42, tiers, tiers, nil, true},
+		{"单元素集合", 9, bigs(9), bigs(9), nil, true},
+		{"负数元素", -7, bigs(3, -7, 11), bigs(3, -7, 11), nil, true},
+		{"负数换成相反数", -7, bigs(3, -7, 11), bigs(3, 7, 11), nil, false},
+		{"验证时换了集合", 7, tiers, bigs(3, 8, 11, 19, 42), nil, false},
+		{"篡改zd", 11, tiers, tiers, func(proof *MembershipProof) { proof.zd = addInP(proof.zd, big.NewInt(1)) }, false},
+		{"篡改f", 11, tiers, tiers, func(proof *MembershipProof) { proof.f[0] = addInP(proof.f[0], big.NewInt(1)) }, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			G, H := GeneratePoint(), GeneratePoint()
+			v, gamma := big.NewInt(test.v), GenerateRandomScalar()
+			proof, err := ProveMembership(G, H, v, gamma, test.set, newTranscript("membership test"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.tamper != nil {
+				test.tamper(&proof)
+			}
+			V := CommitCT(G, H, modN(v).Bytes(), gamma.Bytes())
+			err = VerifyMembership(G, H, V, test.verifySet, proof, newTranscript("membership test"))
+			if test.valid && err != nil {
+				t.Fatal(err)
+			}
+			if !test.valid && !IsInvalidProof(err) {
+				t.Fatalf("err = %v，应为证明无效", err)
+			}
+		})
+	}
+}
+
+func TestMembershipInputErrors(t *testing.T) {
+	G, H := GeneratePoint(), GeneratePoint()
+	gamma := GenerateRandomScalar()
+	if _, err := ProveMembership(G, H, big.NewInt(5), gamma, bigs(1, 2, 3), newTranscript("membership test")); !errors.Is(err, ErrNotInSet) {
+		t.Fatalf("err = %v，应为ErrNotInSet", err)
+	}
+	if _, err := ProveMembership(G, H, big.NewInt(-5), gamma, bigs(1, 5), newTranscript("membership test")); !errors.Is(err, ErrNotInSet) {
+		t.Fatalf("-5不是5: err = %v，应为ErrNotInSet", err)
+	}
+	if _, err := ProveMembership(G, H, big.NewInt(5), gamma, nil, newTranscript("membership test")); !errors.Is(err, ErrEmptySet) {
+		t.Fatalf("err = %v，应为ErrEmptySet", err)
+	}
+	proof, err := ProveMembership(G, H, big.NewInt(2), gamma, bigs(1, 2, 3, 4, 5), newTranscript("membership test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	V := CommitCT(G, H, big.NewInt(2).Bytes(), gamma.Bytes())
+	//集合大小不同时证明的长度也不同
+	if err := VerifyMembership(G, H, V, bigs(1, 2), proof, newTranscript("membership test")); !errors.Is(err, ErrMalformedProof) {
+		t.Fatalf("err = %v，应为ErrMalformedProof", err)
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+//Pedersen承诺的Merkle树累加器，按RFC 6962/9162的方式计算哈希：
+//叶子哈希 = SHA256(0x00||V.Bytes())，内部节点 = SHA256(0x01||左||右)，大小不是2的幂时按最大的2的幂切分
+//树只能追加，可以给出某个承诺的包含证明，以及两个历史大小的根之间的一致性证明
+
+type CommitmentTree struct {
+	leaves []Point
+	hashes [][32]byte
+}
+
+func merkleLeafHash(V Point) [32]byte {
+	return sha256.Sum256(append([]byte{0}, V.Bytes()...))
+}
+
+func merkleNodeHash(left [32]byte, right [32]byte) [32]byte {
+	buf := make([]byte, 0, 65)
+	buf = append(buf, 1)
+	buf = append(buf, left[:]...)
+	buf = append(buf, right[:]...)
+	return sha256.Sum256(buf)
+}
+
+//小于n的最大的2的幂，n > 1
+func splitPoint(n int) int {
+	k := 1
+	for k*2 < n {
+		k *= 2
+	}
+	return k
+}
+
+//计算叶子哈希序列的根MTH(D[n])
+func merkleRoot(hashes [][32]byte) [32]byte {
+	switch len(hashes) {
+	case 0:
+		return sha256.Sum256(nil)
+	case 1:
+		return hashes[0]
+	}
+	k := splitPoint(len(hashes))
+	return merkleNodeHash(merkleRoot(hashes[:k]), merkleRoot(hashes[k:]))
+}
+
+//追加一个承诺，返回它的下标
+func (tree *CommitmentTree) Append(V Point) (int, error) {
+	if !IsOnCurve(V) {
+		return 0, fmt.Errorf("%w: 承诺V", ErrInvalidPoint)
+	}
+	tree.leaves = append(tree.leaves, V)
+	tree.hashes = append(tree.hashes, merkleLeafHash(V))
+	return len(tree.leaves) - 1, nil
+}
+
+//验证V在[0,2^n)中的范围证明，通过后再追加
+func (tree *CommitmentTree) AppendVerified(G Point, H Point, GVector []Point, HVector []Point, V Point, n int64, proof RangeProof, transcript *Transcript) (int, error) {
+	if err := VerifyRange(G, H, GVector, HVector, V, n, proof, transcript); err != nil {
+		return 0, err
+	}
+	return tree.Append(V)
+}
+
+//当前的叶子个数
+func (tree CommitmentTree) Size() int {
+	return len(tree.leaves)
+}
+
+//第index个承诺
+func (tree CommitmentTree) Leaf(index int) (Point, error) {
+	if index < 0 || index >= len(tree.leaves) {
+		return Point{}, ErrTreeIndex
+	}
+	return tree.leaves[index], nil
+}
+
+//当前的根
+func (tree CommitmentTree) Root() [32]byte {
+	return merkleRoot(tree.hashes)
+}
+
+//树的大小为size时的根
+func (tree CommitmentTree) RootAt(size int) ([32]byte, error) {
+	if size < 0 || size > len(tree.hashes) {
+		return [32]byte{}, ErrTreeIndex
+	}
+	return merkleRoot(tree.hashes[:size]), nil
+}
+
+//第index个承诺在大小为size的树中的包含证明PATH(index, D[size])
+func (tree CommitmentTree) InclusionProof(index int, size int) ([][32]byte, error) {
+	if size < 0 || size > len(tree.hashes) || index < 0 || index >= size {
+		return nil, ErrTreeIndex
+	}
+	return inclusionPath(index, tree.hashes[:size]), nil
+}
+
+func inclusionPath(m int, hashes [][32]byte) [][32]byte {
+	if len(hashes) <= 1 {
+		return nil
+	}
+	k := splitPoint(len(hashes))
+	if m < k {
+		return append(inclusionPath(m, hashes[:k]), merkleRoot(hashes[k:]))
+	}
+	return append(inclusionPath(m-k, hashes[k:]), merkleRoot(hashes[:k]))
+}
+
+//验证承诺V是大小为size、根为root的树中的第index个叶子
+func VerifyInclusion(V Point, index int, size int, path [][32]byte, root [32]byte) error {
+	if index < 0 || index >= size {
+		return ErrTreeIndex
+	}
+	fn, sn := index, size-1
+	r := merkleLeafHash(V)
+	for _, p := range path {
+		if sn == 0 {
+			return fmt.Errorf("%w: 包含证明过长", ErrMerkleFailed)
+		}
+		if fn&1 == 1 || fn == sn {
+			r = merkleNodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = merkleNodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 || r != root {
+		return ErrMerkleFailed
+	}
+	return nil
+}
+
+//验证V在树中，并验证V在[0,2^n)中的范围证明
+func VerifyCommittedRange(G Point, H Point, GVector []Point, HVector []Point, V Point, n int64, index int, size int, path [][32]byte, root [32]byte, proof RangeProof, transcript *Transcript) error {
+	if err := VerifyInclusion(V, index, size, path, root); err != nil {
+		return err
+	}
+	return VerifyRange(G, H, GVector, HVector, V, n, proof, transcript)
+}
+
+//大小oldSize和newSize的两个根之间的一致性证明PROOF(oldSize, D[newSize])
+func (tree CommitmentTree) ConsistencyProof(oldSize int, newSize int) ([][32]byte, error) {
+	if oldSize < 0 || oldSize > newSize || newSize > len(tree.hashes) {
+		return nil, ErrTreeIndex
+	}
+	if oldSize == 0 || oldSize == newSize {
+		return nil, nil
+	}
+	return subProof(oldSize, tree.hashes[:newSize], true), nil
+}
+
+func subProof(m int, hashes [][32]byte, complete bool) [][32]byte {
+	n := len(hashes)
+	if m == n {
+		if complete {
+			return nil
+		}
+		return [][32]byte{merkleRoot(hashes)}
+	}
+	k := splitPoint(n)
+	if m <= k {
+		return append(subProof(m, hashes[:k], complete), merkleRoot(hashes[k:]))
+	}
+	return append(subProof(m-k, hashes[k:], false), merkleRoot(hashes[:k]))
+}
+
+//验证大小为newSize的树是在大小为oldSize的树后面追加得到的
+func VerifyConsistency(oldSize int, newSize int, oldRoot [32]byte, newRoot [32]byte, proof [][32]byte) error {
+	if oldSize < 0 || oldSize > newSize {
+		return ErrTreeIndex
+	}
+	if oldSize == 0 || oldSize == newSize {
+		if len(proof) != 0 {
+			return fmt.Errorf("%w: 一致性证明应为空", ErrMerkleFailed)
+		}
+		if oldSize == newSize && oldRoot != newRoot {
+			return ErrMerkleFailed
+		}
+		return nil
+	}
+	if oldSize&(oldSize-1) == 0 {
+		proof = append([][32]byte{oldRoot}, proof...)
+	}
+	if len(proof) == 0 {
+		return fmt.Errorf("%w: 一致性证明为空", ErrMerkleFailed)
+	}
+	fn, sn := oldSize-1, newSize-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return fmt.Errorf("%w: 一致性证明过长", ErrMerkleFailed)
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = merkleNodeHash(c, fr)
+			sr = merkleNodeHash(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = merkleNodeHash(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 || fr != oldRoot || sr != newRoot {
+		return ErrMerkleFailed
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+//由count个随机点组成的树
+func testCommitmentTree(t *testing.T, count int) CommitmentTree {
+	var tree CommitmentTree
+	for i := 0; i < count; i++ {
+		if _, err := tree.Append(GeneratePoint()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tree
+}
+
+func TestMerkleInclusion(t *testing.T) {
+	tree := testCommitmentTree(t, 9)
+	for size := 1; size <= tree.Size(); size++ {
+		root, err := tree.RootAt(size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for index := 0; index < size; index++ {
+			path, err := tree.InclusionProof(index, size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			V, _ := tree.Leaf(index)
+			if err := VerifyInclusion(V, index, size, path, root); err != nil {
+				t.Fatalf("size = %d, index = %d: %v", size, index, err)
+			}
+			if err := VerifyInclusion(V, index, size, append(path, root), root); !errors.Is(err, ErrMerkleFailed) {
+				t.Fatalf("size = %d, index = %d, 路径过长: err = %v", size, index, err)
+			}
+			if size > 1 {
+				if err := VerifyInclusion(V, (index+1)%size, size, path, root); !errors.Is(err, ErrMerkleFailed) {
+					t.Fatalf("size = %d, index = %d, 下标错误: err = %v", size, index, err)
+				}
+				path[0][0] ^= 1
+				if err := VerifyInclusion(V, index, size, path, root); !errors.Is(err, ErrMerkleFailed) {
+					t.Fatalf("size = %d, index = %d, 篡改路径: err = %v", size, index, err)
+				}
+			}
+		}
+	}
+	if _, err := tree.InclusionProof(9, 9); !errors.Is(err, ErrTreeIndex) {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := tree.Append(Point{x: big.NewInt(1), y: big.NewInt(1)}); !errors.Is(err, ErrInvalidPoint) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestMerkleConsistency(t *testing.T) {
+	tree := testCommitmentTree(t, 9)
+	for newSize := 0; newSize <= tree.Size(); newSize++ {
+		newRoot, _ := tree.RootAt(newSize)
+		for oldSize := 0; oldSize <= newSize; oldSize++ {
+			oldRoot, _ := tree.RootAt(oldSize)
+			proof, err := tree.ConsistencyProof(oldSize, newSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := VerifyConsistency(oldSize, newSize, oldRoot, newRoot, proof); err != nil {
+				t.Fatalf("%d -> %d: %v", oldSize, newSize, err)
+			}
+			if oldSize == 0 || oldSize == newSize {
+				continue
+			}
+			wrongRoot := oldRoot
+			wrongRoot[0] ^= 1
+			if err := VerifyConsistency(oldSize, newSize, wrongRoot, newRoot, proof); !errors.Is(err, ErrMerkleFailed) {
+				t.Fatalf("%d -> %d, 旧根错误: err = %v", oldSize, newSize, err)
+			}
+			if len(proof) > 0 {
+				proof[len(proof)-1][0] ^= 1
+				if err := VerifyConsistency(oldSize, newSize, oldRoot, newRoot, proof); !errors.Is(err, ErrMerkleFailed) {
+					t.Fatalf("%d -> %d, 篡改证明: err = %v", oldSize, newSize, err)
+				}
+			}
+		}
+	}
+	if _, err := tree.ConsistencyProof(5, 4); !errors.Is(err, ErrTreeIndex) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestCommittedRange(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(32)
+	tree := testCommitmentTree(t, 3)
+	v, gamma := big.NewInt(77), GenerateRandomScalar()
+	V := CommitCT(G, H, v.Bytes(), gamma.Bytes())
+	proof, err := ProveRange(ModeBulletproofs, G, H, GVector, HVector, v, gamma, 8, newTranscript("merkle test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tree.AppendVerified(G, H, GVector, HVector, V, 16, proof, newTranscript("merkle test")); !errors.Is(err, ErrMalformedProof) {
+		t.Fatalf("要求的范围不同: err = %v", err)
+	}
+	if tree.Size() != 3 {
+		t.Fatal("未通过验证的承诺被追加到了树中")
+	}
+	index, err := tree.AppendVerified(G, H, GVector, HVector, V, 8, proof, newTranscript("merkle test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree.Append(GeneratePoint())
+	size, root := tree.Size(), tree.Root()
+	path, _ := tree.InclusionProof(index, size)
+	other, _ := tree.Leaf(0)
+
+	tests := []struct {
+		name    string
+		V       Point
+		n       int64
+		wantErr error
+		reject  bool
+	}{
+		{"诚实的证明", V, 8, nil, false},
+		{"要求的范围更宽", V, 16, ErrMalformedProof, false},
+		{"要求的范围更窄", V, 4, ErrMalformedProof, false},
+		{"n = 256", V, 256, ErrInvalidRangeWidth, false},
+		{"承诺不在树中", other, 8, ErrMerkleFailed, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := VerifyCommittedRange(G, H, GVector, HVector, test.V, test.n, index, size, path, root, proof, newTranscript("merkle test"))
+			if !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil) {
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+		})
+	}
+}
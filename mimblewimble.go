@@ -0,0 +1,251 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/decred/dcrd/dcrec/secp256k1/v3"
+	"github.com/decred/dcrd/dcrec/secp256k1/v3/schnorr"
+	"math/big"
+)
+
+//Mimblewimble交易：输出承诺为v*G + r*H，交易满足Σ输出 - Σ输入 + fee*G = Σexcess + offset*H
+//excess = (Σr_out - Σr_in - offset)*H，用它对应的私钥对kernel签名（decred的schnorr），证明差值中没有金额
+//为了让excess能作为schnorr公钥，盲因子生成元H必须是secp256k1的基点，值生成元G由哈希得到
+//多个交易可以直接合并（拼接输入、输出、kernel，offset相加），再把相互抵消的输入输出删掉（cut-through）
+
+type Kernel struct {
+	Fee       *big.Int
+	Excess    Point
+	Signature *schnorr.Signature
+}
+
+type MWTransaction struct {
+	Offset  *big.Int
+	Inputs  []Point
+	Outputs []Output
+	Kernels []Kernel
+}
+
+//Mimblewimble使用的参数：H是secp256k1的基点，G没有已知的离散对数关系
+func NewMimblewimbleParams(mode RangeProofMode, n int64) LedgerParams {
+	return LedgerParams{
+		Mode:    mode,
+		G:       AssetGenerator([]byte("mimblewimble value")),
+		H:       basePoint(),
+		GVector: GenerateMultiPoint(n),
+		HVector: GenerateMultiPoint(n),
+		N:       n,
+	}
+}
+
+//kernel签名的消息覆盖手续费和excess，签名不能挪到别的kernel上
+func kernelMessage(fee *big.Int, excess Point) []byte {
+	h := sha256.New()
+	writeWithLength(h.Write, []byte("mimblewimble kernel"))
+	writeWithLength(h.Write, ScalarBytes(fee))
+	writeWithLength(h.Write, excess.Bytes())
+	return h.Sum(nil)
+}
+
+//构造单个交易，要求Σ输入 = Σ输出 + fee
+func (params LedgerParams) NewMWTransaction(inputs []OutputOpening, outputs []OutputOpening, fee *big.Int) (MWTransaction, error) {
+	if !IsEqual(params.H, basePoint()) {
+		return MWTransaction{}, ErrNotMimblewimble
+	}
+	if fee.Sign() < 0 {
+		return MWTransaction{}, ErrValueOutOfRange
+	}
+	tx := MWTransaction{}
+	sum := big.NewInt(0).Neg(fee)
+	blinding := big.NewInt(0)
+	for _, input := range inputs {
+		tx.Inputs = append(tx.Inputs, CommitCT(params.G, params.H, input.Value.Bytes(), modN(input.Gamma).Bytes()))
+		sum.Add(sum, input.Value)
+		blinding = subInP(blinding, modN(input.Gamma))
+	}
+	for _, opening := range outputs {
+		output, err := params.NewOutput(opening)
+		if err != nil {
+			return MWTransaction{}, err
+		}
+		tx.Outputs = append(tx.Outputs, output)
+		sum.Sub(sum, opening.Value)
+		blinding = addInP(blinding, modN(opening.Gamma))
+	}
+	if sum.Sign() != 0 {
+		return MWTransaction{}, ErrUnbalanced
+	}
+
+	//excess = Σr_out - Σr_in - offset，offset随机，使交易合并后无法按kernel拆分
+	var excess *big.Int
+	for excess == nil || excess.Sign() == 0 {
+		tx.Offset = GenerateRandomScalar()
+		excess = subInP(blinding, tx.Offset)
+	}
+	kernel, err := signKernel(excess, fee)
+	if err != nil {
+		return MWTransaction{}, err
+	}
+	tx.Kernels = []Kernel{kernel}
+	return tx, nil
+}
+
+//用excess对应的私钥对kernel签名
+func signKernel(excess *big.Int, fee *big.Int) (Kernel, error) {
+	priv := secp256k1.PrivKeyFromBytes(ScalarBytes(excess))
+	defer priv.Zero()
+	X := CommitSingleCT(basePoint(), excess.Bytes())
+	signature, err := schnorr.Sign(priv, kernelMessage(fee, X))
+	if err != nil {
+		return Kernel{}, err
+	}
+	return Kernel{Fee: big.NewInt(0).Set(fee), Excess: X, Signature: signature}, nil
+}
+
+//验证kernel的签名
+func (kernel Kernel) Verify() error {
+	if kernel.Fee == nil || kernel.Fee.Sign() < 0 || kernel.Fee.Cmp(curve.N) >= 0 {
+		return ErrValueOutOfRange
+	}
+	if kernel.Signature == nil {
+		return fmt.Errorf("%w: 缺少kernel签名", ErrMalformedProof)
+	}
+	pub, err := secp256k1.ParsePubKey(kernel.Excess.Bytes())
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPoint, err)
+	}
+	if !kernel.Signature.Verify(kernelMessage(kernel.Fee, kernel.Excess), pub) {
+		return ErrKernelFailed
+	}
+	return nil
+}
+
+//kernel按excess区分，同一个kernel出现两次说明同一个交易被合并了两次
+func checkKernels(kernels []Kernel) error {
+	seen := make(map[string]bool)
+	for _, kernel := range kernels {
+		key := outputKey(kernel.Excess)
+		if seen[key] {
+			return fmt.Errorf("%w: %x", ErrDuplicateKernel, kernel.Excess.Bytes())
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+//合并多个交易，kernel不能重复
+func AggregateMW(txs ...MWTransaction) (MWTransaction, error) {
+	aggregate := MWTransaction{Offset: big.NewInt(0)}
+	for _, tx := range txs {
+		aggregate.Offset = addInP(aggregate.Offset, tx.Offset)
+		aggregate.Inputs = append(aggregate.Inputs, tx.Inputs...)
+		aggregate.Outputs = append(aggregate.Outputs, tx.Outputs...)
+		aggregate.Kernels = append(aggregate.Kernels, tx.Kernels...)
+	}
+	if err := checkKernels(aggregate.Kernels); err != nil {
+		return MWTransaction{}, err
+	}
+	return aggregate, nil
+}
+
+//cut-through：删除在同一个交易中既被创建又被花费的输出，平衡关系不变
+func (tx MWTransaction) CutThrough() MWTransaction {
+	created := make(map[string]int)
+	for _, output := range tx.Outputs {
+		created[outputKey(output.V)]++
+	}
+	spent := make(map[string]int)
+	result := MWTransaction{Offset: tx.Offset, Kernels: tx.Kernels}
+	for _, input := range tx.Inputs {
+		key := outputKey(input)
+		if created[key] > 0 {
+			created[key]--
+			spent[key]++
+			continue
+		}
+		result.Inputs = append(result.Inputs, input)
+	}
+	for _, output := range tx.Outputs {
+		key := outputKey(output.V)
+		if spent[key] > 0 {
+			spent[key]--
+			continue
+		}
+		result.Outputs = append(result.Outputs, output)
+	}
+	return result
+}
+
+//验证交易或区块本身：kernel签名且不重复、输出的范围证明、平衡关系，以及已经做过cut-through
+//ledger的参数必须是Mimblewimble参数
+func (ledger *Ledger) ValidateMW(tx MWTransaction) error {
+	params := ledger.params
+	if !IsEqual(params.H, basePoint()) {
+		return ErrNotMimblewimble
+	}
+	if len(tx.Kernels) == 0 {
+		return fmt.Errorf("%w: 没有kernel", ErrMalformedProof)
+	}
+	if tx.Offset == nil || tx.Offset.Sign() < 0 || tx.Offset.Cmp(curve.N) >= 0 {
+		return fmt.Errorf("%w: offset超出范围", ErrMalformedProof)
+	}
+	if err := checkKernels(tx.Kernels); err != nil {
+		return err
+	}
+	created := make(map[string]bool)
+	for _, output := range tx.Outputs {
+		created[outputKey(output.V)] = true
+	}
+	inputs := make(map[string]bool)
+	for _, input := range tx.Inputs {
+		key := outputKey(input)
+		if created[key] {
+			return fmt.Errorf("%w: 输入与输出%x相同，需要先cut-through", ErrMalformedProof, input.Bytes())
+		}
+		if inputs[key] || ledger.spent[key] {
+			return ErrDoubleSpend
+		}
+		if _, ok := ledger.unspent[key]; !ok {
+			return ErrUnknownOutput
+		}
+		inputs[key] = true
+	}
+	seen := make(map[string]bool)
+	for _, output := range tx.Outputs {
+		if err := ledger.checkOutput(output, seen); err != nil {
+			return err
+		}
+	}
+
+	//Σ输出 - Σ输入 + Σfee*G = Σexcess + offset*H
+	minusOne := negBig(big.NewInt(1)).Bytes()
+	left := Point{x: big.NewInt(0), y: big.NewInt(0)}
+	right := CommitSingle(params.H, tx.Offset.Bytes())
+	fee := big.NewInt(0)
+	for _, kernel := range tx.Kernels {
+		if err := kernel.Verify(); err != nil {
+			return err
+		}
+		right = MultiCommit(right, kernel.Excess)
+		fee = addInP(fee, kernel.Fee)
+	}
+	for _, output := range tx.Outputs {
+		left = MultiCommit(left, output.V)
+	}
+	for _, input := range tx.Inputs {
+		left = MultiCommit(left, CommitSingle(input, minusOne))
+	}
+	left = MultiCommit(left, CommitSingle(params.G, fee.Bytes()))
+	if !IsEqual(left, right) {
+		return ErrUnbalanced
+	}
+	return nil
+}
+
+//验证区块并更新未花费输出
+func (ledger *Ledger) ApplyMW(block MWTransaction) error {
+	if err := ledger.ValidateMW(block); err != nil {
+		return err
+	}
+	return ledger.spend(block.Inputs, block.Outputs)
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+//Mimblewimble账本，发行一个金额为v的输出作为初始的未花费输出
+func testMWLedger(t *testing.T, v int64) (*Ledger, LedgerParams, OutputOpening) {
+	params := NewMimblewimbleParams(ModeBulletproofs, 16)
+	issuerKey, issuer := GenerateKeyPair()
+	params.Issuer = issuer
+	var ledger Ledger
+	if err := ledger.New(params, ""); err != nil {
+		t.Fatal(err)
+	}
+	return &ledger, params, issueTestOutput(t, &ledger, params, issuerKey, v)
+}
+
+func testOpening(v int64) OutputOpening {
+	return OutputOpening{Value: big.NewInt(v), Gamma: GenerateRandomScalar()}
+}
+
+func TestMWTransaction(t *testing.T) {
+	ledger, params, input := testMWLedger(t, 100)
+	tx, err := params.NewMWTransaction([]OutputOpening{input}, []OutputOpening{testOpening(70), testOpening(20)}, big.NewInt(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := params.NewMWTransaction([]OutputOpening{input}, []OutputOpening{testOpening(90)}, big.NewInt(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		tamper  func(tx *MWTransaction)
+		wantErr error
+	}{
+		{"诚实的交易", nil, nil},
+		{"篡改手续费", func(tx *MWTransaction) { tx.Kernels[0].Fee = big.NewInt(11) }, ErrKernelFailed},
+		{"换成别的交易的excess", func(tx *MWTransaction) { tx.Kernels[0].Excess = other.Kernels[0].Excess }, ErrKernelFailed},
+		{"换成别的交易的签名", func(tx *MWTransaction) { tx.Kernels[0].Signature = other.Kernels[0].Signature }, ErrKernelFailed},
+		{"重复的kernel", func(tx *MWTransaction) { tx.Kernels = append(tx.Kernels, tx.Kernels[0]) }, ErrDuplicateKernel},
+		{"篡改offset", func(tx *MWTransaction) { tx.Offset = addInP(tx.Offset, big.NewInt(1)) }, ErrUnbalanced},
+		{"删掉一个输出", func(tx *MWTransaction) { tx.Outputs = tx.Outputs[:1] }, ErrUnbalanced},
+		{"没有kernel", func(tx *MWTransaction) { tx.Kernels = nil }, ErrMalformedProof},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tampered := tx
+			tampered.Outputs = append([]Output{}, tx.Outputs...)
+			tampered.Kernels = append([]Kernel{}, tx.Kernels...)
+			if test.tamper != nil {
+				test.tamper(&tampered)
+			}
+			err := ledger.ValidateMW(tampered)
+			if !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil) {
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+		})
+	}
+
+	if err := ledger.ApplyMW(tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := ledger.ApplyMW(other); !errors.Is(err, ErrDoubleSpend) {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := params.NewMWTransaction([]OutputOpening{input}, []OutputOpening{testOpening(90)}, big.NewInt(11)); !errors.Is(err, ErrUnbalanced) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestAggregateMW(t *testing.T) {
+	ledger, params, input := testMWLedger(t, 100)
+	middle := testOpening(95)
+	tx1, err := params.NewMWTransaction([]OutputOpening{input}, []OutputOpening{middle}, big.NewInt(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx2, err := params.NewMWTransaction([]OutputOpening{middle}, []OutputOpening{testOpening(60), testOpening(30)}, big.NewInt(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := AggregateMW(tx1, tx2, tx1); !errors.Is(err, ErrDuplicateKernel) {
+		t.Fatalf("重复合并同一个交易: err = %v", err)
+	}
+	block, err := AggregateMW(tx1, tx2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ledger.ValidateMW(block); !errors.Is(err, ErrMalformedProof) {
+		t.Fatalf("没有cut-through: err = %v", err)
+	}
+	block = block.CutThrough()
+	if len(block.Inputs) != 1 || len(block.Outputs) != 2 || len(block.Kernels) != 2 {
+		t.Fatalf("cut-through之后有%d个输入、%d个输出、%d个kernel", len(block.Inputs), len(block.Outputs), len(block.Kernels))
+	}
+	if err := ledger.ApplyMW(block); err != nil {
+		t.Fatal(err)
+	}
+	if ledger.Size() != 2 {
+		t.Fatalf("账本中有%d个输出", ledger.Size())
+	}
+}
+
+func TestMWRequiresBasePoint(t *testing.T) {
+	params, _ := testLedgerParams()
+	if _, err := params.NewMWTransaction(nil, nil, big.NewInt(0)); !errors.Is(err, ErrNotMimblewimble) {
+		t.Fatalf("err = %v", err)
+	}
+}
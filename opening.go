@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+)
+
+//公开值的打开证明：公开v但不公开gamma，证明V = v*G + gamma*H
+//P = V - v*G = gamma*H，用Schnorr协议证明知道P对H的离散对数
+//交互形式：prover发送R = k*H，verifier发送挑战c，prover回复s = k + c*gamma，verifier检查s*H = R + c*P
+
+type OpeningProof struct {
+	R Point
+	s *big.Int
+}
+
+//计算P = V - v*G
+func openingTarget(G Point, V Point, v *big.Int) Point {
+	return MultiCommit(V, CommitSingle(G, negBig(modN(v)).Bytes()))
+}
+
+//检查s*H = R + c*P
+func checkOpening(H Point, P Point, R Point, c *big.Int, s *big.Int) error {
+	if !IsOnCurve(R) {
+		return fmt.Errorf("%w: %v", ErrMalformedProof, ErrInvalidPoint)
+	}
+	if s == nil || s.Sign() < 0 || s.Cmp(curve.N) >= 0 {
+		return fmt.Errorf("%w: 标量超出范围", ErrMalformedProof)
+	}
+	if !IsEqual(CommitSingle(H, s.Bytes()), MultiCommit(R, CommitSingle(P, c.Bytes()))) {
+		return ErrOpeningFailed
+	}
+	return nil
+}
+
+type OpeningProver struct {
+	H     Point
+	gamma *big.Int
+	k     *big.Int
+}
+
+func (prover *OpeningProver) New(H Point, gamma *big.Int) {
+	prover.H = H
+	prover.gamma = modN(gamma)
+	prover.k = nil
+}
+
+//第一步：生成随机数k，发送R = k*H
+func (prover *OpeningProver) Commit() Point {
+	prover.k = GenerateRandomScalar()
+	return CommitSingleCT(prover.H, prover.k.Bytes())
+}
+
+//第二步：收到挑战c后回复s = k + c*gamma，k只能使用一次
+func (prover *OpeningProver) Respond(c *big.Int) (*big.Int, error) {
+	if prover.k == nil {
+		return nil, ErrMissingState
+	}
+	s := addInP(prover.k, mulInP(c, prover.gamma))
+	prover.k.SetInt64(0)
+	prover.k = nil
+	return s, nil
+}
+
+type OpeningVerifier struct {
+	H Point
+	P Point
+	R Point
+	c *big.Int
+}
+
+//V是要检查的承诺，v是公开的值
+func (verifier *OpeningVerifier) New(G Point, H Point, V Point, v *big.Int) error {
+	if !IsOnCurve(V) {
+		return fmt.Errorf("%w: 承诺V", ErrInvalidPoint)
+	}
+	verifier.H = H
+	verifier.P = openingTarget(G, V, v)
+	verifier.c = nil
+	return nil
+}
+
+//收到R后生成随机挑战c
+func (verifier *OpeningVerifier) Challenge(R Point) (*big.Int, error) {
+	if !IsOnCurve(R) {
+		return nil, fmt.Errorf("%w: R", ErrInvalidPoint)
+	}
+	verifier.R = R
+	verifier.c = GenerateRandomScalar()
+	return verifier.c, nil
+}
+
+//检查prover的回复s
+func (verifier *OpeningVerifier) Verify(s *big.Int) error {
+	if verifier.c == nil {
+		return ErrMissingState
+	}
+	return checkOpening(verifier.H, verifier.P, verifier.R, verifier.c, s)
+}
+
+func appendOpeningStatement(transcript *Transcript, V Point, v *big.Int) {
+	transcript.AppendBytes("opening", []byte{1})
+	transcript.AppendPoint("V", V)
+	transcript.AppendScalar("v", v)
+}
+
+//非交互形式：挑战c由transcript生成
+func ProveOpening(G Point, H Point, v *big.Int, gamma *big.Int, transcript *Transcript) OpeningProof {
+	V := CommitCT(G, H, modN(v).Bytes(), modN(gamma).Bytes())
+	appendOpeningStatement(transcript, V, v)
+
+	var prover OpeningProver
+	prover.New(H, gamma)
+	var proof OpeningProof
+	proof.R = prover.Commit()
+	transcript.AppendPoint("R", proof.R)
+	c := transcript.ChallengeScalar("c")
+	proof.s, _ = prover.Respond(c)
+	return proof
+}
+
+//验证V是对公开值v的承诺
+func VerifyOpening(G Point, H Point, V Point, v *big.Int, proof OpeningProof, transcript *Transcript) error {
+	if !IsOnCurve(V) {
+		return fmt.Errorf("%w: 承诺V", ErrInvalidPoint)
+	}
+	appendOpeningStatement(transcript, V, v)
+	transcript.AppendPoint("R", proof.R)
+	c := transcript.ChallengeScalar("c")
+	return checkOpening(H, openingTarget(G, V, v), proof.R, c, proof.s)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestOpening(t *testing.T) {
+	G, H := GeneratePoint(), GeneratePoint()
+	tests := []struct {
+		name    string
+		v       int64
+		claimed int64
+		tamper  func(proof *OpeningProof)
+		wantErr error
+	}{
+		{"打开正确", 42, 42, nil, nil},
+		{"负数", -7, -7, nil, nil},
+		{"公开的值不对", 42, 43, nil, ErrOpeningFailed},
+		{"篡改s", 42, 42, func(proof *OpeningProof) { proof.s = addInP(proof.s, big.NewInt(1)) }, ErrOpeningFailed},
+		{"篡改R", 42, 42, func(proof *OpeningProof) { proof.R = G }, ErrOpeningFailed},
+		{"s超出Zp", 42, 42, func(proof *OpeningProof) { proof.s = big.NewInt(0).Set(curve.N) }, ErrMalformedProof},
+		{"R不在曲线上", 42, 42, func(proof *OpeningProof) { proof.R = Point{} }, ErrMalformedProof},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gamma := GenerateRandomScalar()
+			proof := ProveOpening(G, H, big.NewInt(test.v), gamma, newTranscript("opening test"))
+			if test.tamper != nil {
+				test.tamper(&proof)
+			}
+			V := CommitCT(G, H, modN(big.NewInt(test.v)).Bytes(), gamma.Bytes())
+			err := VerifyOpening(G, H, V, big.NewInt(test.claimed), proof, newTranscript("opening test"))
+			if !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil) {
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestOpeningInteractive(t *testing.T) {
+	G, H := GeneratePoint(), GeneratePoint()
+	v, gamma := big.NewInt(5), GenerateRandomScalar()
+	V := CommitCT(G, H, v.Bytes(), gamma.Bytes())
+
+	var prover OpeningProver
+	var verifier OpeningVerifier
+	prover.New(H, gamma)
+	if err := verifier.New(G, H, V, v); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifier.Verify(big.NewInt(1)); !errors.Is(err, ErrMissingState) {
+		t.Fatalf("没有挑战就验证: err = %v", err)
+	}
+	c, err := verifier.Challenge(prover.Commit())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := prover.Respond(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifier.Verify(s); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := prover.Respond(c); !errors.Is(err, ErrMissingState) {
+		t.Fatalf("k重复使用: err = %v", err)
+	}
+	if err := verifier.Verify(addInP(s, big.NewInt(1))); !IsInvalidProof(err) {
+		t.Fatalf("err = %v", err)
+	}
+}
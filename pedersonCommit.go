@@ -86,6 +86,22 @@ func IsEqual(commit0 Point, commit1 Point) bool {
 	return false
 }
 
+//点的规范编码：压缩格式，0x02/0x03加32字节的x坐标，无穷远点编码为33个0
+func (p Point) Bytes() []byte {
+	buf := make([]byte, 33)
+	if p.x == nil || p.y == nil || (p.x.Sign() == 0 && p.y.Sign() == 0) {
+		return buf
+	}
+	buf[0] = 0x02 + byte(p.y.Bit(0))
+	p.x.FillBytes(buf[1:])
+	return buf
+}
+
+//判断点是否在椭圆曲线上
+func IsOnCurve(p Point) bool {
+	return p.x != nil && p.y != nil && curve.IsOnCurve(p.x, p.y)
+}
+
 //两个承诺相乘（在椭圆曲线中，是两个点相加）
 func MultiCommit(commit0 Point, commit1 Point) (commit Point) {
 	commit.x, commit.y = curve.Add(commit0.x, commit0.y, commit1.x, commit1.y)
@@ -124,6 +140,16 @@ func addInP(a *big.Int, b *big.Int) *big.Int {
 	return c
 }
 
+//把可能为负或超出Zp的数映射到[0,N)，Bytes()会丢掉符号，负数在承诺前必须先这样处理
+func modN(a *big.Int) *big.Int {
+	return big.NewInt(0).Mod(a, curve.N)
+}
+
+//计算a-b
+func subInP(a *big.Int, b *big.Int) *big.Int {
+	return addInP(a, negBig(b))
+}
+
 func mulInP(a *big.Int, b *big.Int) *big.Int {
 	var m,n secp256k1.ModNScalar
 	c := big.NewInt(0)
@@ -131,8 +157,7 @@ func mulInP(a *big.Int, b *big.Int) *big.Int {
 	m.SetByteSlice(a.Bytes())
 	n.SetByteSlice(b.Bytes())
 
-	m.Mul(&n)
-	mbyte := m.Bytes()
+	mbyte := scalarMulCT(&m, &n).Bytes()
 	c.SetBytes(mbyte[0:32])
 	return c
 }
@@ -180,7 +205,7 @@ func new() {
 	fmt.Println(a1)
 	fmt.Println(a2.Bytes())
 	m.InverseNonConst()
-	commit0 := CommitSingle(prover.H, big.NewInt(4).Bytes())
+	commit0 := CommitSingle(proverSession.prover.H, big.NewInt(4).Bytes())
 	b := m.Bytes()
 	fmt.Println(big.NewInt(2).Bytes())
 	fmt.Println(b)
@@ -190,5 +215,5 @@ func new() {
 	fmt.Println(b2.Bytes())
 	commit1 := CommitSingle(commit0, b2.Bytes())
 	fmt.Println(commit1.x)
-	fmt.Println(prover.H.x)
+	fmt.Println(proverSession.prover.H.x)
 }
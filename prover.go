@@ -1,7 +1,6 @@
 package main
 
 import (
-	"errors"
 	"fmt"
 	"github.com/decred/dcrd/dcrec/secp256k1/v3"
 	"math/big"
@@ -15,7 +14,7 @@ type Prover struct {
 	curve            *secp256k1.KoblitzCurve
 
 	//v是需要进行范围证明的值，V是对v的承诺
-	v int64
+	v *big.Int
 	V Point
 
 	//和A,S承诺相关的参数
@@ -51,15 +50,21 @@ type ProverZKP struct {
 	V      Point
 }
 
-func (prover *Prover) New(G Point, H Point, GVector []Point, HVector []Point, v int64, n int64,curve secp256k1.KoblitzCurve) error {
+func (prover *Prover) New(G Point, H Point, GVector []Point, HVector []Point, v *big.Int, n int64,curve secp256k1.KoblitzCurve) error {
+	if !IsValidRangeWidth(n) {
+		return ErrInvalidRangeWidth
+	}
+	if v == nil || v.Sign() < 0 {
+		return ErrValueOutOfRange
+	}
 	if int64(len(GVector)) < n || int64(len(HVector)) < n {
-		return errors.New("G,H矢量的长度不足n位，无法提供证明")
+		return ErrGeneratorsTooShort
 	}
 	prover.G = G
 	prover.H = H
 	prover.GVector = GVector[:n]
 	prover.HVector = HVector[:n]
-	prover.v = v
+	prover.v = big.NewInt(0).Set(v)
 	prover.n = n
 	prover.curve = &curve
 
@@ -67,36 +72,37 @@ func (prover *Prover) New(G Point, H Point, GVector []Point, HVector []Point, v
 }
 
 //用于获取承诺A和承诺S
-func (prover *Prover) GetAS() (Point, Point) {
-	prover.generateAS()
-	return prover.A, prover.S
+func (prover *Prover) GetAS() (Point, Point, error) {
+	if err := prover.generateAS(); err != nil {
+		return Point{}, Point{}, err
+	}
+	return prover.A, prover.S, nil
 }
 
 //生成aL,aR,sL,sR以及A承诺,S承诺
-func (prover *Prover) generateAS() {
-	err := errors.New("")
-
+func (prover *Prover) generateAS() error {
 	//生成aL,aR两个矢量
-	prover.aL, err = GenerateA_L(big.NewInt(prover.v), prover.n)
+	aL, err := GenerateA_LCT(prover.v, prover.n)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return err
 	}
+	prover.aL = aL
 	prover.sL = GenerateS(prover.n) //在此生成sL是因为以当前的系统时间为种子，生成的随机数，如果sL和sR生成的间隔很近，会导致两个矢量重复。
 	prover.aR = GenerateA_R(prover.aL)
 
 	//生成承诺A
 	prover.alpha = GenerateRandom()
-	commitA := CommitVectors(prover.GVector, prover.HVector, prover.aL, prover.aR)
-	commitAlpha := CommitSingle(prover.H, []byte{prover.alpha})
-	prover.A.x, prover.A.y = curve.Add(commitA.x, commitA.y, commitAlpha.x, commitAlpha.y)
+	commitA := CommitVectorsCT(prover.GVector, prover.HVector, prover.aL, prover.aR)
+	commitAlpha := CommitSingleCT(prover.H, []byte{prover.alpha})
+	prover.A = MultiCommitCT(commitA, commitAlpha)
 
 	//生成承诺S
 	prover.rho = GenerateRandom()
 	prover.sR = GenerateS(prover.n)
-	commitS := CommitVectors(prover.GVector, prover.HVector, prover.sL, prover.sR)
-	commitRho := CommitSingle(prover.H, []byte{prover.rho})
-	prover.S.x, prover.S.y = curve.Add(commitS.x, commitS.y, commitRho.x, commitRho.y)
+	commitS := CommitVectorsCT(prover.GVector, prover.HVector, prover.sL, prover.sR)
+	commitRho := CommitSingleCT(prover.H, []byte{prover.rho})
+	prover.S = MultiCommitCT(commitS, commitRho)
+	return nil
 }
 
 //计算t(x)中的t1,t2两个系数
@@ -134,16 +140,19 @@ func (prover *Prover) calculateT() {
 }
 
 //用于获取承诺T1,T2
-func (prover *Prover) GetT() (Point, Point) {
+func (prover *Prover) GetT() (Point, Point, error) {
+	if prover.aL == nil || prover.sL == nil {
+		return Point{}, Point{}, fmt.Errorf("%w: 尚未生成承诺A,S", ErrMissingState)
+	}
 	prover.generateT()
-	return prover.T1, prover.T2
+	return prover.T1, prover.T2, nil
 }
 
 //生成T1,T2两个承诺
 func (prover *Prover) generateT() {
 	prover.calculateT()
-	prover.T2 = Commit(prover.G, prover.H, prover.t2.Bytes(), big.NewInt(int64(prover.tau2)).Bytes())
-	prover.T1 = Commit(prover.G, prover.H, prover.t1.Bytes(), big.NewInt(int64(prover.tau1)).Bytes())
+	prover.T2 = CommitCT(prover.G, prover.H, prover.t2.Bytes(), big.NewInt(int64(prover.tau2)).Bytes())
+	prover.T1 = CommitCT(prover.G, prover.H, prover.t1.Bytes(), big.NewInt(int64(prover.tau1)).Bytes())
 }
 
 //计算l(x)
@@ -188,11 +197,14 @@ func (prover *Prover) calculateMju() {
 
 //生成关于V的承诺
 func (prover *Prover) generateV() {
-	v := big.NewInt(prover.v)
-	prover.V = Commit(prover.G, prover.H, v.Bytes(), big.NewInt(int64(prover.gamma)).Bytes())
+	prover.V = CommitCT(prover.G, prover.H, prover.v.Bytes(), big.NewInt(int64(prover.gamma)).Bytes())
 }
 
-func (prover *Prover) GetProverZKP() ProverZKP {
+//回应挑战x后立即清除本次使用的随机数，再次调用返回ErrMissingState，不能对其他x重复作答
+func (prover *Prover) GetProverZKP() (ProverZKP, error) {
+	if prover.t1 == nil || prover.t2 == nil {
+		return ProverZKP{}, fmt.Errorf("%w: 尚未生成承诺T1,T2", ErrMissingState)
+	}
 	prover.calculateMju()
 	prover.calculateTaux()
 	prover.calculateLx()
@@ -208,5 +220,16 @@ func (prover *Prover) GetProverZKP() ProverZKP {
 		rx:   prover.rx,
 		V:    prover.V,
 	}
-	return proverZKP
+	prover.clearSecrets()
+	return proverZKP, nil
+}
+
+//回应挑战后清除本次证明使用的随机数和秘密矢量，避免对不同的x重复作答
+func (prover *Prover) clearSecrets() {
+	prover.alpha, prover.rho = 0, 0
+	prover.tau1, prover.tau2 = 0, 0
+	prover.gamma = 0
+	prover.aL, prover.aR = nil, nil
+	prover.sL, prover.sR = nil, nil
+	prover.t1, prover.t2 = nil, nil
 }
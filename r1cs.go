@@ -0,0 +1,411 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+)
+
+//R1CS约束系统：乘法门aL∘aR = aO，以及形如Σ系数·变量 = 0的线性约束
+//线性约束可以引用承诺的高层变量v、乘法门的输入输出以及常数1，合起来即Bulletproofs论文中的
+//WL·aL + WR·aR + WO·aO = WV·v + c
+
+type variableKind int
+
+const (
+	varOne       variableKind = iota //常数1
+	varCommitted                     //承诺的高层变量v_j
+	varMultLeft                      //第i个乘法门的左输入aL_i
+	varMultRight                     //第i个乘法门的右输入aR_i
+	varMultOutput                    //第i个乘法门的输出aO_i
+)
+
+//约束系统中的变量
+type Variable struct {
+	kind  variableKind
+	index int
+}
+
+//常数1
+var VariableOne = Variable{kind: varOne}
+
+type lcTerm struct {
+	variable Variable
+	coeff    *big.Int
+}
+
+//变量的线性组合
+type LinearCombination struct {
+	terms []lcTerm
+}
+
+//由单个变量构成的线性组合
+func (variable Variable) LC() LinearCombination {
+	return LinearCombination{terms: []lcTerm{{variable, big.NewInt(1)}}}
+}
+
+//常数c构成的线性组合
+func Constant(c *big.Int) LinearCombination {
+	return VariableOne.LC().Times(c)
+}
+
+func (lc LinearCombination) Add(other LinearCombination) LinearCombination {
+	terms := append(append([]lcTerm{}, lc.terms...), other.terms...)
+	return LinearCombination{terms: terms}
+}
+
+func (lc LinearCombination) Sub(other LinearCombination) LinearCombination {
+	return lc.Add(other.Times(negBig(big.NewInt(1))))
+}
+
+func (lc LinearCombination) Times(c *big.Int) LinearCombination {
+	var terms []lcTerm
+	for _, term := range lc.terms {
+		terms = append(terms, lcTerm{term.variable, mulInP(term.coeff, c)})
+	}
+	return LinearCombination{terms: terms}
+}
+
+//prover和verifier共用的约束接口，gadget只依赖这个接口编写
+type ConstraintSystem interface {
+	//分配一个乘法门并约束left*right = output，返回门的左输入、右输入和输出
+	Multiply(left LinearCombination, right LinearCombination) (Variable, Variable, Variable)
+	//分配一个乘法门，输入由prover直接给出，verifier一方传nil
+	Allocate(left *big.Int, right *big.Int) (Variable, Variable, Variable, error)
+	//添加约束lc = 0
+	Constrain(lc LinearCombination)
+}
+
+//R1CS的证明
+type R1CSProof struct {
+	AI, AO, S          Point
+	T1, T3, T4, T5, T6 Point
+	taux, mju, tx      *big.Int
+	lx, rx             []*big.Int
+}
+
+//prover和verifier共享的部分：公开参数和约束
+type r1cs struct {
+	G, H             Point
+	GVector, HVector []Point
+
+	numMul       int
+	numCommitted int
+	constraints  []LinearCombination
+}
+
+func (cs *r1cs) allocate() (Variable, Variable, Variable) {
+	i := cs.numMul
+	cs.numMul++
+	return Variable{varMultLeft, i}, Variable{varMultRight, i}, Variable{varMultOutput, i}
+}
+
+func (cs *r1cs) Constrain(lc LinearCombination) {
+	cs.constraints = append(cs.constraints, lc)
+}
+
+//把所有约束按z的幂合并：wL,wR,wO,wV分别是z^(q+1)·WL等，wc是z^(q+1)·c
+func (cs *r1cs) flatten(z *big.Int) (wL, wR, wO, wV []*big.Int, wc *big.Int) {
+	zeros := func(n int) []*big.Int {
+		var v []*big.Int
+		for i := 0; i < n; i++ {
+			v = append(v, big.NewInt(0))
+		}
+		return v
+	}
+	wL, wR, wO = zeros(cs.numMul), zeros(cs.numMul), zeros(cs.numMul)
+	wV = zeros(cs.numCommitted)
+	wc = big.NewInt(0)
+
+	zq := big.NewInt(1)
+	for _, lc := range cs.constraints {
+		zq = mulInP(zq, z)
+		for _, term := range lc.terms {
+			coeff := mulInP(zq, term.coeff)
+			i := term.variable.index
+			switch term.variable.kind {
+			case varMultLeft:
+				wL[i] = addInP(wL[i], coeff)
+			case varMultRight:
+				wR[i] = addInP(wR[i], coeff)
+			case varMultOutput:
+				wO[i] = addInP(wO[i], coeff)
+			case varCommitted:
+				wV[i] = subInP(wV[i], coeff)
+			case varOne:
+				wc = subInP(wc, coeff)
+			}
+		}
+	}
+	return wL, wR, wO, wV, wc
+}
+
+//把约束系统的规模和承诺写入transcript
+func (cs *r1cs) appendStatement(transcript *Transcript, V []Point) {
+	transcript.AppendInt("m", int64(cs.numCommitted))
+	transcript.AppendInt("n", int64(cs.numMul))
+	for _, value := range V {
+		transcript.AppendPoint("V", value)
+	}
+}
+
+type R1CSProver struct {
+	r1cs
+
+	//承诺的高层变量及其盲化因子
+	v, gamma []*big.Int
+	V        []Point
+
+	//乘法门的赋值
+	aL, aR, aO []*big.Int
+}
+
+type R1CSVerifier struct {
+	r1cs
+
+	V []Point
+}
+
+func (prover *R1CSProver) New(G Point, H Point, GVector []Point, HVector []Point) {
+	*prover = R1CSProver{}
+	prover.G, prover.H = G, H
+	prover.GVector, prover.HVector = GVector, HVector
+}
+
+//为高层变量v提供承诺V = v*G + gamma*H，返回承诺和对应的变量
+func (prover *R1CSProver) Commit(v *big.Int, gamma *big.Int) (Point, Variable) {
+	V := CommitCT(prover.G, prover.H, v.Bytes(), gamma.Bytes())
+	prover.v = append(prover.v, v)
+	prover.gamma = append(prover.gamma, gamma)
+	prover.V = append(prover.V, V)
+	prover.numCommitted++
+	return V, Variable{varCommitted, prover.numCommitted - 1}
+}
+
+//计算线性组合在当前赋值下的值
+func (prover *R1CSProver) eval(lc LinearCombination) *big.Int {
+	sum := big.NewInt(0)
+	for _, term := range lc.terms {
+		var value *big.Int
+		i := term.variable.index
+		switch term.variable.kind {
+		case varOne:
+			value = big.NewInt(1)
+		case varCommitted:
+			value = prover.v[i]
+		case varMultLeft:
+			value = prover.aL[i]
+		case varMultRight:
+			value = prover.aR[i]
+		case varMultOutput:
+			value = prover.aO[i]
+		}
+		sum = addInP(sum, mulInP(term.coeff, value))
+	}
+	return sum
+}
+
+func (prover *R1CSProver) Allocate(left *big.Int, right *big.Int) (Variable, Variable, Variable, error) {
+	if left == nil || right == nil {
+		return Variable{}, Variable{}, Variable{}, fmt.Errorf("%w: prover分配乘法门时需要给出输入", ErrMissingState)
+	}
+	l, r, o := prover.allocate()
+	prover.aL = append(prover.aL, addInP(left, big.NewInt(0)))
+	prover.aR = append(prover.aR, addInP(right, big.NewInt(0)))
+	prover.aO = append(prover.aO, mulInP(left, right))
+	return l, r, o, nil
+}
+
+func (prover *R1CSProver) Multiply(left LinearCombination, right LinearCombination) (Variable, Variable, Variable) {
+	l, r, o, _ := prover.Allocate(prover.eval(left), prover.eval(right))
+	prover.Constrain(left.Sub(l.LC()))
+	prover.Constrain(right.Sub(r.LC()))
+	return l, r, o
+}
+
+//生成R1CS的证明
+func (prover *R1CSProver) Prove(transcript *Transcript) (R1CSProof, error) {
+	//没有乘法门时补一个0*0=0，保证矢量非空
+	if prover.numMul == 0 {
+		prover.Allocate(big.NewInt(0), big.NewInt(0))
+	}
+	n := int64(prover.numMul)
+	if int64(len(prover.GVector)) < n || int64(len(prover.HVector)) < n {
+		return R1CSProof{}, ErrGeneratorsTooShort
+	}
+	GVector := prover.GVector[:n]
+	HVector := prover.HVector[:n]
+	prover.appendStatement(transcript, prover.V)
+
+	//承诺AI,AO,S
+	var proof R1CSProof
+	alpha := GenerateRandomScalar()
+	beta := GenerateRandomScalar()
+	rho := GenerateRandomScalar()
+	sL := GenerateRandomVector(n)
+	sR := GenerateRandomVector(n)
+	proof.AI = MultiCommitCT(CommitSingleCT(prover.H, alpha.Bytes()), CommitVectorsCT(GVector, HVector, prover.aL, prover.aR))
+	proof.AO = MultiCommitCT(CommitSingleCT(prover.H, beta.Bytes()), CommitSingleVectorCT(GVector, prover.aO))
+	proof.S = MultiCommitCT(CommitSingleCT(prover.H, rho.Bytes()), CommitVectorsCT(GVector, HVector, sL, sR))
+	transcript.AppendPoint("AI", proof.AI)
+	transcript.AppendPoint("AO", proof.AO)
+	transcript.AppendPoint("S", proof.S)
+
+	y := transcript.ChallengeScalar("y")
+	z := transcript.ChallengeScalar("z")
+	yn := GenerateYBig(y, n)
+	yInvN := GenerateYBig(inverseBig(y), n)
+	wL, wR, wO, wV, _ := prover.flatten(z)
+
+	//l(X) = l1*X + l2*X^2 + l3*X^3, r(X) = r0 + r1*X + r3*X^3
+	l1 := CalVectorAdd(prover.aL, CalHadamardVectorBig(yInvN, wR))
+	l2 := prover.aO
+	l3 := sL
+	r0 := CalVectorSub(wO, yn)
+	r1 := CalVectorAdd(CalHadamardVectorBig(yn, prover.aR), wL)
+	r3 := CalHadamardVectorBig(yn, sR)
+
+	//t(X) = <l(X),r(X)>的各项系数，t2由约束决定，不需要承诺
+	t := map[int]*big.Int{
+		1: Inner_ProofBig(l1, r0),
+		3: addInP(Inner_ProofBig(l2, r1), Inner_ProofBig(l3, r0)),
+		4: addInP(Inner_ProofBig(l1, r3), Inner_ProofBig(l3, r1)),
+		5: Inner_ProofBig(l2, r3),
+		6: Inner_ProofBig(l3, r3),
+	}
+	tau := map[int]*big.Int{}
+	T := map[int]*Point{1: &proof.T1, 3: &proof.T3, 4: &proof.T4, 5: &proof.T5, 6: &proof.T6}
+	for _, i := range []int{1, 3, 4, 5, 6} {
+		tau[i] = GenerateRandomScalar()
+		*T[i] = CommitCT(prover.G, prover.H, t[i].Bytes(), tau[i].Bytes())
+		transcript.AppendPoint(fmt.Sprintf("T%d", i), *T[i])
+	}
+
+	x := transcript.ChallengeScalar("x")
+	xn := GenerateYBig(x, 7)
+
+	proof.lx = CalVectorAdd(CalVectorAdd(CalVectorTimesBig(l1, xn[1]), CalVectorTimesBig(l2, xn[2])), CalVectorTimesBig(l3, xn[3]))
+	proof.rx = CalVectorAdd(CalVectorAdd(r0, CalVectorTimesBig(r1, xn[1])), CalVectorTimesBig(r3, xn[3]))
+	proof.tx = Inner_ProofBig(proof.lx, proof.rx)
+
+	taux := mulInP(xn[2], Inner_ProofBig(wV, prover.gamma))
+	for _, i := range []int{1, 3, 4, 5, 6} {
+		taux = addInP(taux, mulInP(tau[i], xn[i]))
+	}
+	proof.taux = taux
+	proof.mju = addInP(addInP(mulInP(alpha, xn[1]), mulInP(beta, xn[2])), mulInP(rho, xn[3]))
+
+	transcript.AppendScalar("taux", proof.taux)
+	transcript.AppendScalar("mju", proof.mju)
+	transcript.AppendScalar("tx", proof.tx)
+	return proof, nil
+}
+
+func (verifier *R1CSVerifier) New(G Point, H Point, GVector []Point, HVector []Point) {
+	*verifier = R1CSVerifier{}
+	verifier.G, verifier.H = G, H
+	verifier.GVector, verifier.HVector = GVector, HVector
+}
+
+//登记prover发送的承诺V，返回对应的变量
+func (verifier *R1CSVerifier) Commit(V Point) Variable {
+	verifier.V = append(verifier.V, V)
+	verifier.numCommitted++
+	return Variable{varCommitted, verifier.numCommitted - 1}
+}
+
+func (verifier *R1CSVerifier) Allocate(left *big.Int, right *big.Int) (Variable, Variable, Variable, error) {
+	l, r, o := verifier.allocate()
+	return l, r, o, nil
+}
+
+func (verifier *R1CSVerifier) Multiply(left LinearCombination, right LinearCombination) (Variable, Variable, Variable) {
+	l, r, o := verifier.allocate()
+	verifier.Constrain(left.Sub(l.LC()))
+	verifier.Constrain(right.Sub(r.LC()))
+	return l, r, o
+}
+
+//检查证明是否完整
+func (verifier *R1CSVerifier) checkProof(proof R1CSProof) error {
+	for _, p := range append([]Point{proof.AI, proof.AO, proof.S, proof.T1, proof.T3, proof.T4, proof.T5, proof.T6}, verifier.V...) {
+		if !IsOnCurve(p) {
+			return fmt.Errorf("%w: %v", ErrMalformedProof, ErrInvalidPoint)
+		}
+	}
+	if len(proof.lx) != verifier.numMul || len(proof.rx) != verifier.numMul {
+		return fmt.Errorf("%w: l(x),r(x)的长度不等于乘法门的个数", ErrMalformedProof)
+	}
+	scalars := append([]*big.Int{proof.taux, proof.mju, proof.tx}, proof.lx...)
+	for _, value := range append(scalars, proof.rx...) {
+		if value == nil || value.Sign() < 0 || value.Cmp(curve.N) >= 0 {
+			return fmt.Errorf("%w: 标量超出范围", ErrMalformedProof)
+		}
+	}
+	return nil
+}
+
+//验证R1CS的证明，约束必须与prover添加的完全一致
+func (verifier *R1CSVerifier) Verify(proof R1CSProof, transcript *Transcript) error {
+	if verifier.numMul == 0 {
+		verifier.Allocate(nil, nil)
+	}
+	n := int64(verifier.numMul)
+	if int64(len(verifier.GVector)) < n || int64(len(verifier.HVector)) < n {
+		return ErrGeneratorsTooShort
+	}
+	if err := verifier.checkProof(proof); err != nil {
+		return err
+	}
+	GVector := verifier.GVector[:n]
+	verifier.appendStatement(transcript, verifier.V)
+
+	transcript.AppendPoint("AI", proof.AI)
+	transcript.AppendPoint("AO", proof.AO)
+	transcript.AppendPoint("S", proof.S)
+	y := transcript.ChallengeScalar("y")
+	z := transcript.ChallengeScalar("z")
+	T := map[int]Point{1: proof.T1, 3: proof.T3, 4: proof.T4, 5: proof.T5, 6: proof.T6}
+	for _, i := range []int{1, 3, 4, 5, 6} {
+		transcript.AppendPoint(fmt.Sprintf("T%d", i), T[i])
+	}
+	x := transcript.ChallengeScalar("x")
+	transcript.AppendScalar("taux", proof.taux)
+	transcript.AppendScalar("mju", proof.mju)
+	transcript.AppendScalar("tx", proof.tx)
+
+	xn := GenerateYBig(x, 7)
+	yn := GenerateYBig(y, n)
+	yInvN := GenerateYBig(inverseBig(y), n)
+	wL, wR, wO, wV, wc := verifier.flatten(z)
+	h1 := GenerateH1Big(verifier.HVector[:n], y)
+
+	//验证t(x)：tx*G + taux*H = x^2(δ+wc)*G + x^2*<wV,V> + Σx^i*T_i
+	delta := Inner_ProofBig(CalHadamardVectorBig(yInvN, wR), wL)
+	commit0 := Commit(verifier.G, verifier.H, proof.tx.Bytes(), proof.taux.Bytes())
+	commit1 := CommitSingle(verifier.G, mulInP(xn[2], addInP(delta, wc)).Bytes())
+	if len(verifier.V) > 0 {
+		commit1 = MultiCommit(commit1, CommitSingleVector(verifier.V, CalVectorTimesBig(wV, xn[2])))
+	}
+	for _, i := range []int{1, 3, 4, 5, 6} {
+		commit1 = MultiCommit(commit1, CommitSingle(T[i], xn[i].Bytes()))
+	}
+	if !IsEqual(commit0, commit1) {
+		return ErrTxCheckFailed
+	}
+
+	//验证承诺P：x*AI + x^2*AO + x^3*S - h'^(y^n) + g^(x*y^-n∘wR) + h'^(x*wL+wO) = g^l + h'^r + mju*H
+	P := MultiCommit(CommitSingle(proof.AI, xn[1].Bytes()), CommitSingle(proof.AO, xn[2].Bytes()))
+	P = MultiCommit(P, CommitSingle(proof.S, xn[3].Bytes()))
+	P = MultiCommit(P, CommitSingleVector(GVector, CalVectorTimesBig(CalHadamardVectorBig(yInvN, wR), xn[1])))
+	P = MultiCommit(P, CommitSingleVector(h1, CalVectorSub(CalVectorAdd(CalVectorTimesBig(wL, xn[1]), wO), yn)))
+	P1 := MultiCommit(CommitVectors(GVector, h1, proof.lx, proof.rx), CommitSingle(verifier.H, proof.mju.Bytes()))
+	if !IsEqual(P, P1) {
+		return ErrCommitPFailed
+	}
+
+	//验证tx = <l,r>
+	if Inner_ProofBig(proof.lx, proof.rx).Cmp(proof.tx) != 0 {
+		return ErrInnerProductFailed
+	}
+	return nil
+}
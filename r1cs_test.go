@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+//电路：values是prover一方承诺的高层变量，verifier一方为nil
+type testCircuit func(cs ConstraintSystem, vars []Variable, values []*big.Int) error
+
+//a*b = c
+func productCircuit(cs ConstraintSystem, vars []Variable, values []*big.Int) error {
+	ProductGadget(cs, vars[0].LC(), vars[1].LC(), vars[2].LC())
+	return nil
+}
+
+//v在[0,2^8)中
+func rangeCircuit(cs ConstraintSystem, vars []Variable, values []*big.Int) error {
+	var value *big.Int
+	if values != nil {
+		value = values[0]
+	}
+	return RangeGadget(cs, vars[0].LC(), value, 8)
+}
+
+//生成并验证电路的证明，tamper在验证之前修改证明
+func proveCircuit(t *testing.T, circuit testCircuit, values []*big.Int, tamper func(proof *R1CSProof)) error {
+	G, H, GVector, HVector := testGenerators(16)
+	var prover R1CSProver
+	prover.New(G, H, GVector, HVector)
+	var V []Point
+	var vars []Variable
+	for _, value := range values {
+		commit, variable := prover.Commit(value, GenerateRandomScalar())
+		V = append(V, commit)
+		vars = append(vars, variable)
+	}
+	if err := circuit(&prover, vars, values); err != nil {
+		t.Fatal(err)
+	}
+	proof, err := prover.Prove(newTranscript("r1cs test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tamper != nil {
+		tamper(&proof)
+	}
+
+	var verifier R1CSVerifier
+	verifier.New(G, H, GVector, HVector)
+	vars = nil
+	for _, commit := range V {
+		vars = append(vars, verifier.Commit(commit))
+	}
+	if err := circuit(&verifier, vars, nil); err != nil {
+		t.Fatal(err)
+	}
+	return verifier.Verify(proof, newTranscript("r1cs test"))
+}
+
+func TestR1CS(t *testing.T) {
+	one := big.NewInt(1)
+	tests := []struct {
+		name    string
+		circuit testCircuit
+		values  []int64
+		tamper  func(proof *R1CSProof)
+		valid   bool
+	}{
+		{"乘积", productCircuit, []int64{3, 5, 15}, nil, true},
+		{"乘积不成立", productCircuit, []int64{3, 5, 16}, nil, false},
+		{"范围", rangeCircuit, []int64{200}, nil, true},
+		{"超出范围", rangeCircuit, []int64{300}, nil, false},
+		{"篡改tx", productCircuit, []int64{3, 5, 15}, func(proof *R1CSProof) { proof.tx = addInP(proof.tx, one) }, false},
+		{"篡改T3", productCircuit, []int64{3, 5, 15}, func(proof *R1CSProof) { proof.T3 = proof.T4 }, false},
+		{"篡改l(x)", rangeCircuit, []int64{200}, func(proof *R1CSProof) { proof.lx[2] = addInP(proof.lx[2], one) }, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var values []*big.Int
+			for _, value := range test.values {
+				values = append(values, big.NewInt(value))
+			}
+			err := proveCircuit(t, test.circuit, values, test.tamper)
+			if test.valid && err != nil {
+				t.Fatal(err)
+			}
+			if !test.valid && !IsInvalidProof(err) {
+				t.Fatalf("err = %v，应为证明无效", err)
+			}
+		})
+	}
+}
+
+func TestR1CSMalformed(t *testing.T) {
+	err := proveCircuit(t, productCircuit, []*big.Int{big.NewInt(2), big.NewInt(2), big.NewInt(4)}, func(proof *R1CSProof) {
+		proof.rx = proof.rx[:0]
+	})
+	if !errors.Is(err, ErrMalformedProof) {
+		t.Fatalf("err = %v，应为ErrMalformedProof", err)
+	}
+}
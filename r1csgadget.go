@@ -0,0 +1,38 @@
+package main
+
+import (
+	"math/big"
+)
+
+//常用的gadget，只依赖ConstraintSystem接口，prover和verifier调用同一份代码生成相同的约束
+
+//约束a*b = c
+func ProductGadget(cs ConstraintSystem, a LinearCombination, b LinearCombination, c LinearCombination) {
+	_, _, o := cs.Multiply(a, b)
+	cs.Constrain(o.LC().Sub(c))
+}
+
+//约束v在[0,2^n)中，value是v的取值，verifier一方传nil
+func RangeGadget(cs ConstraintSystem, v LinearCombination, value *big.Int, n int) error {
+	sum := LinearCombination{}
+	power := big.NewInt(1)
+	for i := 0; i < n; i++ {
+		var bit, oneMinusBit *big.Int
+		if value != nil {
+			bit = big.NewInt(int64(value.Bit(i)))
+			oneMinusBit = big.NewInt(1 - int64(value.Bit(i)))
+		}
+		//b*(1-b) = 0
+		l, r, o, err := cs.Allocate(bit, oneMinusBit)
+		if err != nil {
+			return err
+		}
+		cs.Constrain(o.LC())
+		cs.Constrain(l.LC().Add(r.LC()).Sub(Constant(big.NewInt(1))))
+
+		sum = sum.Add(l.LC().Times(power))
+		power = mulInP(power, big.NewInt(2))
+	}
+	cs.Constrain(v.Sub(sum))
+	return nil
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+)
+
+//非交互的范围证明：用transcript代替verifier发送的随机数，每个证明可以单独选择模式
+//两种模式共用生成元G,H,GVector,HVector、transcript和序列化格式
+
+type RangeProofMode byte
+
+const (
+	ModeBulletproofs     RangeProofMode = 1 //经典Bulletproofs：A,S,T1,T2，直接发送l(x),r(x)
+	ModeBulletproofsPlus RangeProofMode = 2 //Bulletproofs+：单个承诺A加上加权内积论证
+)
+
+type RangeProof struct {
+	Mode RangeProofMode
+	n    int64
+
+	//ModeBulletproofs
+	A, S, T1, T2  Point
+	taux, mju, tx *big.Int
+	lx, rx        []*big.Int
+
+	//ModeBulletproofsPlus
+	plus BPPlusProof
+}
+
+//把模式、范围和承诺V写入transcript
+func appendRangeStatement(transcript *Transcript, mode RangeProofMode, n int64, V Point) {
+	transcript.AppendBytes("mode", []byte{byte(mode)})
+	transcript.AppendInt("n", n)
+	transcript.AppendPoint("V", V)
+}
+
+//生成V = v*G + gamma*H的非交互范围证明，证明v在[0,2^n)中
+func ProveRange(mode RangeProofMode, G Point, H Point, GVector []Point, HVector []Point, v *big.Int, gamma *big.Int, n int64, transcript *Transcript) (RangeProof, error) {
+	return proveRange(mode, G, H, GVector, HVector, v, gamma, n, nil, transcript)
+}
+
+//derive为nil时经典模式使用随机数，否则用写入陈述后的transcript副本导出随机数（见可回卷的范围证明）
+func proveRange(mode RangeProofMode, G Point, H Point, GVector []Point, HVector []Point, v *big.Int, gamma *big.Int, n int64, derive func(Transcript) classicNonces, transcript *Transcript) (RangeProof, error) {
+	if !IsValidRangeWidth(n) {
+		return RangeProof{}, ErrInvalidRangeWidth
+	}
+	if int64(len(GVector)) < n || int64(len(HVector)) < n {
+		return RangeProof{}, ErrGeneratorsTooShort
+	}
+	if v.Sign() < 0 {
+		return RangeProof{}, ErrValueOutOfRange
+	}
+	GVector, HVector = GVector[:n], HVector[:n]
+	gamma = modN(gamma)
+	V := CommitCT(G, H, v.Bytes(), gamma.Bytes())
+	appendRangeStatement(transcript, mode, n, V)
+
+	proof := RangeProof{Mode: mode, n: n}
+	switch mode {
+	case ModeBulletproofs:
+		var nonces classicNonces
+		if derive != nil {
+			nonces = derive(*transcript)
+		} else {
+			nonces = randomNonces(n)
+		}
+		return proof, proveClassic(G, H, GVector, HVector, v, gamma, n, nonces, transcript, &proof)
+	case ModeBulletproofsPlus:
+		if derive != nil {
+			return RangeProof{}, fmt.Errorf("%w: 只有ModeBulletproofs支持指定随机数", ErrUnknownMode)
+		}
+		if !isPowerOfTwo(n) {
+			return RangeProof{}, ErrNotPowerOfTwo
+		}
+		plus, err := proveBPPlus(G, H, GVector, HVector, v, gamma, n, transcript)
+		proof.plus = plus
+		return proof, err
+	}
+	return RangeProof{}, ErrUnknownMode
+}
+
+//验证非交互范围证明，证明v在[0,2^n)中，n是verifier要求的范围，与证明中的范围不同时拒绝
+func VerifyRange(G Point, H Point, GVector []Point, HVector []Point, V Point, n int64, proof RangeProof, transcript *Transcript) error {
+	if !IsValidRangeWidth(n) {
+		return ErrInvalidRangeWidth
+	}
+	if proof.n != n {
+		return fmt.Errorf("%w: 证明的范围是%d位，要求%d位", ErrMalformedProof, proof.n, n)
+	}
+	if int64(len(GVector)) < n || int64(len(HVector)) < n {
+		return ErrGeneratorsTooShort
+	}
+	if !IsOnCurve(V) {
+		return fmt.Errorf("%w: 承诺V", ErrInvalidPoint)
+	}
+	GVector, HVector = GVector[:n], HVector[:n]
+	appendRangeStatement(transcript, proof.Mode, n, V)
+
+	switch proof.Mode {
+	case ModeBulletproofs:
+		return verifyClassic(G, H, GVector, HVector, V, proof, transcript)
+	case ModeBulletproofsPlus:
+		if !isPowerOfTwo(n) {
+			return ErrNotPowerOfTwo
+		}
+		return verifyBPPlus(G, H, GVector, HVector, V, n, proof.plus, transcript)
+	}
+	return ErrUnknownMode
+}
+
+//证明中的范围n
+func (proof RangeProof) N() int64 {
+	return proof.n
+}
+
+//经典模式中prover使用的随机数
+type classicNonces struct {
+	alpha, rho, tau1, tau2 *big.Int
+	sL, sR                 []*big.Int
+}
+
+func randomNonces(n int64) classicNonces {
+	return classicNonces{
+		alpha: GenerateRandomScalar(),
+		rho:   GenerateRandomScalar(),
+		tau1:  GenerateRandomScalar(),
+		tau2:  GenerateRandomScalar(),
+		sL:    GenerateRandomVector(n),
+		sR:    GenerateRandomVector(n),
+	}
+}
+
+func proveClassic(G Point, H Point, GVector []Point, HVector []Point, v *big.Int, gamma *big.Int, n int64, nonces classicNonces, transcript *Transcript, proof *RangeProof) error {
+	aL, err := GenerateA_LCT(v, n)
+	if err != nil {
+		return err
+	}
+	aR := GenerateA_R(aL)
+	sL, sR := nonces.sL, nonces.sR
+	alpha, rho := nonces.alpha, nonces.rho
+	proof.A = MultiCommitCT(CommitVectorsCT(GVector, HVector, aL, aR), CommitSingleCT(H, alpha.Bytes()))
+	proof.S = MultiCommitCT(CommitVectorsCT(GVector, HVector, sL, sR), CommitSingleCT(H, rho.Bytes()))
+	transcript.AppendPoint("A", proof.A)
+	transcript.AppendPoint("S", proof.S)
+	y := transcript.ChallengeScalar("y")
+	z := transcript.ChallengeScalar("z")
+
+	//l(X) = (aL - z) + sL*X，r(X) = y^n∘(aR + z + sR*X) + z^2*2^n
+	yn := GenerateYBig(y, n)
+	zVector := CalVectorTimesBig(GenerateYBig(big.NewInt(1), n), z)
+	z2 := mulInP(z, z)
+	l0 := CalVectorSub(aL, zVector)
+	r0 := CalVectorAdd(CalHadamardVectorBig(yn, CalVectorAdd(aR, zVector)), CalVectorTimesBig(GenerateY(2, n), z2))
+	r1 := CalHadamardVectorBig(yn, sR)
+	t1 := addInP(Inner_ProofBig(l0, r1), Inner_ProofBig(sL, r0))
+	t2 := Inner_ProofBig(sL, r1)
+	tau1, tau2 := nonces.tau1, nonces.tau2
+	proof.T1 = CommitCT(G, H, t1.Bytes(), tau1.Bytes())
+	proof.T2 = CommitCT(G, H, t2.Bytes(), tau2.Bytes())
+	transcript.AppendPoint("T1", proof.T1)
+	transcript.AppendPoint("T2", proof.T2)
+	x := transcript.ChallengeScalar("x")
+
+	proof.lx = CalVectorAdd(l0, CalVectorTimesBig(sL, x))
+	proof.rx = CalVectorAdd(r0, CalVectorTimesBig(r1, x))
+	proof.tx = Inner_ProofBig(proof.lx, proof.rx)
+	proof.taux = addInP(addInP(mulInP(tau2, mulInP(x, x)), mulInP(tau1, x)), mulInP(z2, gamma))
+	proof.mju = addInP(alpha, mulInP(rho, x))
+	return nil
+}
+
+//按prover的顺序把A,S,T1,T2写入transcript，得到挑战y,z,x
+func classicChallenges(transcript *Transcript, proof RangeProof) (y *big.Int, z *big.Int, x *big.Int) {
+	transcript.AppendPoint("A", proof.A)
+	transcript.AppendPoint("S", proof.S)
+	y = transcript.ChallengeScalar("y")
+	z = transcript.ChallengeScalar("z")
+	transcript.AppendPoint("T1", proof.T1)
+	transcript.AppendPoint("T2", proof.T2)
+	x = transcript.ChallengeScalar("x")
+	return y, z, x
+}
+
+//检查经典模式证明的格式
+func (proof RangeProof) checkClassic() error {
+	n := proof.n
+	for _, p := range []Point{proof.A, proof.S, proof.T1, proof.T2} {
+		if !IsOnCurve(p) {
+			return fmt.Errorf("%w: %v", ErrMalformedProof, ErrInvalidPoint)
+		}
+	}
+	if int64(len(proof.lx)) != n || int64(len(proof.rx)) != n {
+		return fmt.Errorf("%w: l(x),r(x)的长度不等于n", ErrMalformedProof)
+	}
+	for _, value := range append(append([]*big.Int{proof.taux, proof.mju, proof.tx}, proof.lx...), proof.rx...) {
+		if value == nil || value.Sign() < 0 || value.Cmp(curve.N) >= 0 {
+			return fmt.Errorf("%w: 标量超出范围", ErrMalformedProof)
+		}
+	}
+	return nil
+}
+
+func verifyClassic(G Point, H Point, GVector []Point, HVector []Point, V Point, proof RangeProof, transcript *Transcript) error {
+	n := proof.n
+	if err := proof.checkClassic(); err != nil {
+		return err
+	}
+
+	y, z, x := classicChallenges(transcript, proof)
+
+	//tx*G + taux*H = z^2*V + delta*G + x*T1 + x^2*T2，delta = (z-z^2)<1,y^n> - z^3<1,2^n>
+	yn := GenerateYBig(y, n)
+	twoN := GenerateY(2, n)
+	ones := GenerateYBig(big.NewInt(1), n)
+	z2 := mulInP(z, z)
+	delta := subInP(mulInP(subInP(z, z2), Inner_ProofBig(ones, yn)), mulInP(mulInP(z2, z), Inner_ProofBig(ones, twoN)))
+	commit0 := Commit(G, H, proof.tx.Bytes(), proof.taux.Bytes())
+	commit1 := MultiCommit(Commit(V, G, z2.Bytes(), delta.Bytes()), Commit(proof.T1, proof.T2, x.Bytes(), mulInP(x, x).Bytes()))
+	if !IsEqual(commit0, commit1) {
+		return ErrTxCheckFailed
+	}
+
+	//A + x*S - z*<1,g> + <z*y^n + z^2*2^n,h'> = <l,g> + <r,h'> + mju*H
+	h1 := GenerateH1Big(HVector, y)
+	P := MultiCommit(Commit(proof.A, proof.S, big.NewInt(1).Bytes(), x.Bytes()), CommitSingleVector(GVector, CalVectorTimesBig(ones, negBig(z))))
+	P = MultiCommit(P, CommitSingleVector(h1, CalVectorAdd(CalVectorTimesBig(yn, z), CalVectorTimesBig(twoN, z2))))
+	P1 := MultiCommit(CommitVectors(GVector, h1, proof.lx, proof.rx), CommitSingle(H, proof.mju.Bytes()))
+	if !IsEqual(P, P1) {
+		return ErrCommitPFailed
+	}
+
+	if Inner_ProofBig(proof.lx, proof.rx).Cmp(proof.tx) != 0 {
+		return ErrInnerProductFailed
+	}
+	return nil
+}
+
+//序列化：模式(1字节) + n(8字节) + 各模式的字段
+func (proof RangeProof) Bytes() []byte {
+	var w proofWriter
+	w.writeByte(byte(proof.Mode))
+	w.writeInt(proof.n)
+	switch proof.Mode {
+	case ModeBulletproofs:
+		for _, p := range []Point{proof.A, proof.S, proof.T1, proof.T2} {
+			w.writePoint(p)
+		}
+		for _, s := range []*big.Int{proof.taux, proof.mju, proof.tx} {
+			w.writeScalar(s)
+		}
+		w.writeScalars(proof.lx)
+		w.writeScalars(proof.rx)
+	case ModeBulletproofsPlus:
+		w.writePoint(proof.plus.A)
+		w.writePoints(proof.plus.L)
+		w.writePoints(proof.plus.R)
+		w.writePoint(proof.plus.A1)
+		w.writePoint(proof.plus.B)
+		for _, s := range []*big.Int{proof.plus.r1, proof.plus.s1, proof.plus.delta1} {
+			w.writeScalar(s)
+		}
+	}
+	return w.buf
+}
+
+//从字节恢复范围证明
+func ParseRangeProof(b []byte) (RangeProof, error) {
+	r := proofReader{buf: b}
+	var proof RangeProof
+	proof.Mode = RangeProofMode(r.readByte())
+	proof.n = r.readInt()
+	switch proof.Mode {
+	case ModeBulletproofs:
+		proof.A, proof.S, proof.T1, proof.T2 = r.readPoint(), r.readPoint(), r.readPoint(), r.readPoint()
+		proof.taux, proof.mju, proof.tx = r.readScalar(), r.readScalar(), r.readScalar()
+		proof.lx = r.readScalars()
+		proof.rx = r.readScalars()
+	case ModeBulletproofsPlus:
+		proof.plus.A = r.readPoint()
+		proof.plus.L = r.readPoints()
+		proof.plus.R = r.readPoints()
+		proof.plus.A1, proof.plus.B = r.readPoint(), r.readPoint()
+		proof.plus.r1, proof.plus.s1, proof.plus.delta1 = r.readScalar(), r.readScalar(), r.readScalar()
+	default:
+		if r.err == nil {
+			return RangeProof{}, ErrUnknownMode
+		}
+	}
+	if err := r.finish(); err != nil {
+		return RangeProof{}, err
+	}
+	return proof, nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+var rangeProofModes = []struct {
+	name string
+	mode RangeProofMode
+}{
+	{"Bulletproofs", ModeBulletproofs},
+	{"Bulletproofs+", ModeBulletproofsPlus},
+}
+
+func TestRangeProof(t *testing.T) {
+	one := big.NewInt(1)
+	G, H, GVector, HVector := testGenerators(64)
+	tests := []struct {
+		name    string
+		v       *big.Int
+		n       int64
+		verifyN int64
+		tamper  func(proof *RangeProof)
+		wantErr error
+		reject  bool
+	}{
+		{"零", big.NewInt(0), 8, 8, nil, nil, false},
+		{"最大值", big.NewInt(255), 8, 8, nil, nil, false},
+		{"64位", big.NewInt(0).SetUint64(1<<63 + 5), 64, 64, nil, nil, false},
+		{"要求的范围更宽", big.NewInt(100), 8, 16, nil, ErrMalformedProof, false},
+		{"要求的范围更窄", big.NewInt(100), 16, 8, nil, ErrMalformedProof, false},
+		{"篡改证明中的n", big.NewInt(100), 8, 16, func(proof *RangeProof) { proof.n = 16 }, nil, true},
+		{"篡改标量", big.NewInt(100), 8, 8, func(proof *RangeProof) {
+			if proof.Mode == ModeBulletproofs {
+				proof.tx = addInP(proof.tx, one)
+			} else {
+				proof.plus.r1 = addInP(proof.plus.r1, one)
+			}
+		}, nil, true},
+	}
+	for _, mode := range rangeProofModes {
+		for _, test := range tests {
+			t.Run(mode.name+"/"+test.name, func(t *testing.T) {
+				gamma := GenerateRandomScalar()
+				proof, err := ProveRange(mode.mode, G, H, GVector, HVector, test.v, gamma, test.n, newTranscript("range test"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if test.tamper != nil {
+					test.tamper(&proof)
+				}
+				V := CommitCT(G, H, test.v.Bytes(), gamma.Bytes())
+				err = VerifyRange(G, H, GVector, HVector, V, test.verifyN, proof, newTranscript("range test"))
+				switch {
+				case test.reject:
+					if err == nil {
+						t.Fatal("篡改后的证明通过了验证")
+					}
+				case test.wantErr != nil:
+					if !errors.Is(err, test.wantErr) {
+						t.Fatalf("err = %v，应为%v", err, test.wantErr)
+					}
+				case err != nil:
+					t.Fatal(err)
+				}
+			})
+		}
+	}
+}
+
+func TestRangeProofWrongCommitment(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(8)
+	for _, mode := range rangeProofModes {
+		gamma := GenerateRandomScalar()
+		proof, err := ProveRange(mode.mode, G, H, GVector, HVector, big.NewInt(7), gamma, 8, newTranscript("range test"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		V := CommitCT(G, H, big.NewInt(8).Bytes(), gamma.Bytes())
+		if err := VerifyRange(G, H, GVector, HVector, V, 8, proof, newTranscript("range test")); !IsInvalidProof(err) {
+			t.Fatalf("%s: err = %v", mode.name, err)
+		}
+	}
+}
+
+func TestRangeProofInputErrors(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(16)
+	gamma := GenerateRandomScalar()
+	tests := []struct {
+		name    string
+		mode    RangeProofMode
+		v       *big.Int
+		n       int64
+		wantErr error
+	}{
+		{"超出范围", ModeBulletproofs, big.NewInt(256), 8, ErrValueOutOfRange},
+		{"负数", ModeBulletproofsPlus, big.NewInt(-1), 8, ErrValueOutOfRange},
+		{"生成元不足", ModeBulletproofs, big.NewInt(1), 32, ErrGeneratorsTooShort},
+		{"Bulletproofs+要求2的幂", ModeBulletproofsPlus, big.NewInt(1), 10, ErrNotPowerOfTwo},
+		{"未知模式", RangeProofMode(9), big.NewInt(1), 8, ErrUnknownMode},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ProveRange(test.mode, G, H, GVector, HVector, test.v, gamma, test.n, newTranscript("range test"))
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestRangeProofSerialization(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(16)
+	for _, mode := range rangeProofModes {
+		gamma := GenerateRandomScalar()
+		proof, err := ProveRange(mode.mode, G, H, GVector, HVector, big.NewInt(1000), gamma, 16, newTranscript("range test"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b := proof.Bytes()
+		parsed, err := ParseRangeProof(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(parsed.Bytes(), b) {
+			t.Fatalf("%s: 解析后重新编码的结果不同", mode.name)
+		}
+		V := CommitCT(G, H, big.NewInt(1000).Bytes(), gamma.Bytes())
+		if err := VerifyRange(G, H, GVector, HVector, V, 16, parsed, newTranscript("range test")); err != nil {
+			t.Fatalf("%s: %v", mode.name, err)
+		}
+		if _, err := ParseRangeProof(b[:len(b)-1]); err == nil {
+			t.Fatalf("%s: 截断的数据解析成功", mode.name)
+		}
+		if _, err := ParseRangeProof(append(b, 0)); err == nil {
+			t.Fatalf("%s: 有多余数据时解析成功", mode.name)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+//偿付能力证明（Provisions方式的Merkle和树）
+//每个客户的负债l_i承诺为C_i = l_i*G + r_i*H，作为叶子；内部节点的承诺是两个子节点承诺之和，哈希同时覆盖子节点哈希和承诺
+//每个节点都带有[0,2^n)的范围证明，保证没有负数负债被用来抵消总额
+//根节点的承诺是总负债的承诺，交易所可以用打开证明公开总额；客户只拿到自己的打开和路径上兄弟节点的承诺，看不到别人的余额
+
+type Liability struct {
+	ID      string
+	Balance *big.Int
+}
+
+type SumNode struct {
+	C     Point
+	Hash  [32]byte
+	Proof RangeProof
+}
+
+//客户验证自己的负债被计入总额所需的数据
+type LiabilityInclusion struct {
+	Index    int
+	Nonce    []byte
+	Gamma    *big.Int
+	Leaf     SumNode
+	Siblings []SumNode
+	Path     []SumNode //叶子和根之间的节点，自下而上
+}
+
+type SolvencyTree struct {
+	G, H             Point
+	GVector, HVector []Point
+	n                int64
+	levels           [][]SumNode //levels[0]是叶子，最后一层是根
+	gammas           [][]*big.Int
+	nonces           [][]byte
+	index            map[string]int
+	total            *big.Int
+}
+
+//节点范围证明使用的transcript，节点的承诺已经包含在范围证明的陈述中
+func solvencyTranscript() *Transcript {
+	var t Transcript
+	t.New("solvency node")
+	return &t
+}
+
+func hashParts(parts ...[]byte) [32]byte {
+	h := sha256.New()
+	for _, part := range parts {
+		writeWithLength(h.Write, part)
+	}
+	var out [32]byte
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+func leafHash(id string, nonce []byte, C Point) [32]byte {
+	return hashParts([]byte("leaf"), []byte(id), nonce, C.Bytes())
+}
+
+func nodeHash(left [32]byte, right [32]byte, C Point) [32]byte {
+	return hashParts([]byte("node"), left[:], right[:], C.Bytes())
+}
+
+//根据客户负债建立和树，叶子补齐到2的幂，补齐的叶子负债为0
+func (tree *SolvencyTree) New(mode RangeProofMode, G Point, H Point, GVector []Point, HVector []Point, n int64, liabilities []Liability) error {
+	if len(liabilities) == 0 {
+		return ErrEmptySet
+	}
+	tree.G, tree.H, tree.GVector, tree.HVector, tree.n = G, H, GVector, HVector, n
+	tree.index = make(map[string]int)
+	size := 1
+	for size < len(liabilities) {
+		size *= 2
+	}
+
+	var leaves []SumNode
+	var gammas []*big.Int
+	var balances []*big.Int
+	tree.nonces = nil
+	for i := 0; i < size; i++ {
+		id, balance := "", big.NewInt(0)
+		if i < len(liabilities) {
+			id, balance = liabilities[i].ID, liabilities[i].Balance
+			if _, ok := tree.index[id]; ok {
+				return fmt.Errorf("%w: %q", ErrDuplicateLiability, id)
+			}
+			tree.index[id] = i
+		}
+		nonce := make([]byte, 32)
+		if _, err := rand.Read(nonce); err != nil {
+			return err
+		}
+		gamma := GenerateRandomScalar()
+		node, err := tree.newNode(mode, balance, gamma)
+		if err != nil {
+			return fmt.Errorf("客户%q: %w", id, err)
+		}
+		node.Hash = leafHash(id, nonce, node.C)
+		leaves = append(leaves, node)
+		gammas = append(gammas, gamma)
+		balances = append(balances, balance)
+		tree.nonces = append(tree.nonces, nonce)
+	}
+	tree.levels = [][]SumNode{leaves}
+	tree.gammas = [][]*big.Int{gammas}
+
+	for len(balances) > 1 {
+		var level []SumNode
+		var nextGammas, nextBalances []*big.Int
+		children := tree.levels[len(tree.levels)-1]
+		for i := 0; i < len(balances); i += 2 {
+			balance := big.NewInt(0).Add(balances[i], balances[i+1])
+			gamma := addInP(gammas[i], gammas[i+1])
+			node, err := tree.newNode(mode, balance, gamma)
+			if err != nil {
+				return err
+			}
+			node.Hash = nodeHash(children[i].Hash, children[i+1].Hash, node.C)
+			level = append(level, node)
+			nextGammas = append(nextGammas, gamma)
+			nextBalances = append(nextBalances, balance)
+		}
+		tree.levels = append(tree.levels, level)
+		tree.gammas = append(tree.gammas, nextGammas)
+		gammas, balances = nextGammas, nextBalances
+	}
+	tree.total = balances[0]
+	return nil
+}
+
+//生成节点的承诺和范围证明
+func (tree *SolvencyTree) newNode(mode RangeProofMode, balance *big.Int, gamma *big.Int) (SumNode, error) {
+	proof, err := ProveRange(mode, tree.G, tree.H, tree.GVector, tree.HVector, balance, gamma, tree.n, solvencyTranscript())
+	if err != nil {
+		return SumNode{}, err
+	}
+	return SumNode{C: CommitCT(tree.G, tree.H, balance.Bytes(), gamma.Bytes()), Proof: proof}, nil
+}
+
+//公开的根节点
+func (tree SolvencyTree) Root() SumNode {
+	return tree.levels[len(tree.levels)-1][0]
+}
+
+//公开总负债，并证明根节点的承诺确实是对它的承诺
+func (tree SolvencyTree) ProveTotal(transcript *Transcript) (*big.Int, OpeningProof) {
+	gamma := tree.gammas[len(tree.gammas)-1][0]
+	return big.NewInt(0).Set(tree.total), ProveOpening(tree.G, tree.H, tree.total, gamma, transcript)
+}
+
+//为客户id生成包含证明
+func (tree SolvencyTree) Inclusion(id string) (LiabilityInclusion, error) {
+	index, ok := tree.index[id]
+	if !ok {
+		return LiabilityInclusion{}, fmt.Errorf("%w: %q", ErrLiabilityNotFound, id)
+	}
+	inclusion := LiabilityInclusion{
+		Index: index,
+		Nonce: tree.nonces[index],
+		Gamma: tree.gammas[0][index],
+		Leaf:  tree.levels[0][index],
+	}
+	for _, level := range tree.levels[:len(tree.levels)-1] {
+		inclusion.Siblings = append(inclusion.Siblings, level[index^1])
+		index /= 2
+	}
+	index = inclusion.Index
+	for _, level := range tree.levels[1 : len(tree.levels)-1] {
+		index /= 2
+		inclusion.Path = append(inclusion.Path, level[index])
+	}
+	return inclusion, nil
+}
+
+//验证节点的范围证明，范围必须是约定的n
+func verifySumNode(G Point, H Point, GVector []Point, HVector []Point, n int64, node SumNode) error {
+	return VerifyRange(G, H, GVector, HVector, node.C, n, node.Proof, solvencyTranscript())
+}
+
+//公众验证根节点的范围证明
+func VerifySolvencyRoot(G Point, H Point, GVector []Point, HVector []Point, n int64, root SumNode) error {
+	return verifySumNode(G, H, GVector, HVector, n, root)
+}
+
+//公众验证公开的总负债
+func VerifySolvencyTotal(G Point, H Point, root SumNode, total *big.Int, proof OpeningProof, transcript *Transcript) error {
+	return VerifyOpening(G, H, root.C, total, proof, transcript)
+}
+
+//客户验证自己的余额balance被计入了公开的根节点
+//检查叶子是对balance的承诺，路径上每个节点的承诺是子节点之和、哈希正确，叶子、兄弟节点和路径上的节点都在范围内
+func VerifyLiability(G Point, H Point, GVector []Point, HVector []Point, n int64, root SumNode, id string, balance *big.Int, inclusion LiabilityInclusion) error {
+	if inclusion.Gamma == nil || balance.Sign() < 0 {
+		return fmt.Errorf("%w: 缺少盲因子或余额为负", ErrMalformedProof)
+	}
+	if inclusion.Index < 0 || inclusion.Index>>uint(len(inclusion.Siblings)) != 0 {
+		return fmt.Errorf("%w: 叶子位置与路径长度不符", ErrMalformedProof)
+	}
+	if len(inclusion.Siblings) > 0 && len(inclusion.Path) != len(inclusion.Siblings)-1 {
+		return fmt.Errorf("%w: 路径节点的个数与兄弟节点不符", ErrMalformedProof)
+	}
+	node := inclusion.Leaf
+	if !IsOnCurve(node.C) || !IsEqual(node.C, Commit(G, H, balance.Bytes(), modN(inclusion.Gamma).Bytes())) {
+		return fmt.Errorf("%w: 叶子不是对余额的承诺", ErrSolvencyFailed)
+	}
+	if node.Hash != leafHash(id, inclusion.Nonce, node.C) {
+		return fmt.Errorf("%w: 叶子哈希错误", ErrSolvencyFailed)
+	}
+	if err := verifySumNode(G, H, GVector, HVector, n, node); err != nil {
+		return err
+	}
+
+	index := inclusion.Index
+	for i, sibling := range inclusion.Siblings {
+		if !IsOnCurve(sibling.C) {
+			return fmt.Errorf("%w: %v", ErrMalformedProof, ErrInvalidPoint)
+		}
+		if err := verifySumNode(G, H, GVector, HVector, n, sibling); err != nil {
+			return err
+		}
+		left, right := node, sibling
+		if index&1 == 1 {
+			left, right = sibling, node
+		}
+		C := MultiCommit(left.C, right.C)
+		node = SumNode{C: C, Hash: nodeHash(left.Hash, right.Hash, C)}
+		index /= 2
+		//内部节点的承诺由子节点算出，范围证明由交易所提供，两者必须一致
+		if i < len(inclusion.Path) {
+			if node.Hash != inclusion.Path[i].Hash || !IsEqual(node.C, inclusion.Path[i].C) {
+				return fmt.Errorf("%w: 路径节点与子节点之和不符", ErrSolvencyFailed)
+			}
+			node = inclusion.Path[i]
+			if err := verifySumNode(G, H, GVector, HVector, n, node); err != nil {
+				return err
+			}
+		}
+	}
+	if node.Hash != root.Hash || !IsEqual(node.C, root.C) {
+		return fmt.Errorf("%w: 路径计算出的根与公开的根不符", ErrSolvencyFailed)
+	}
+	return VerifySolvencyRoot(G, H, GVector, HVector, n, root)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestSolvencyTree(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(16)
+	liabilities := []Liability{
+		{"alice", big.NewInt(10)},
+		{"bob", big.NewInt(20)},
+		{"carol", big.NewInt(30)},
+		{"dave", big.NewInt(40)},
+		{"erin", big.NewInt(50)},
+	}
+	var tree SolvencyTree
+	if err := tree.New(ModeBulletproofs, G, H, GVector, HVector, 16, liabilities); err != nil {
+		t.Fatal(err)
+	}
+	root := tree.Root()
+	total, opening := tree.ProveTotal(newTranscript("solvency test"))
+	if total.Int64() != 150 {
+		t.Fatalf("总负债为%v", total)
+	}
+	if err := VerifySolvencyTotal(G, H, root, total, opening, newTranscript("solvency test")); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifySolvencyTotal(G, H, root, big.NewInt(149), opening, newTranscript("solvency test")); !errors.Is(err, ErrOpeningFailed) {
+		t.Fatalf("err = %v", err)
+	}
+	for _, liability := range liabilities {
+		inclusion, err := tree.Inclusion(liability.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := VerifyLiability(G, H, GVector, HVector, 16, root, liability.ID, liability.Balance, inclusion); err != nil {
+			t.Fatalf("%s: %v", liability.ID, err)
+		}
+	}
+	if _, err := tree.Inclusion("mallory"); !errors.Is(err, ErrLiabilityNotFound) {
+		t.Fatalf("err = %v", err)
+	}
+	if err := tree.New(ModeBulletproofs, G, H, GVector, HVector, 16, []Liability{{"alice", big.NewInt(1)}, {"alice", big.NewInt(2)}}); !errors.Is(err, ErrDuplicateLiability) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestVerifyLiability(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(16)
+	liabilities := []Liability{
+		{"alice", big.NewInt(10)},
+		{"bob", big.NewInt(20)},
+		{"carol", big.NewInt(30)},
+		{"dave", big.NewInt(40)},
+	}
+	var tree SolvencyTree
+	if err := tree.New(ModeBulletproofs, G, H, GVector, HVector, 8, liabilities); err != nil {
+		t.Fatal(err)
+	}
+	//alice和bob的父节点是alice路径上唯一的内部节点
+	parentGamma := tree.gammas[1][0]
+	tests := []struct {
+		name    string
+		id      string
+		balance *big.Int
+		n       int64
+		tamper  func(inclusion *LiabilityInclusion)
+		wantErr error
+		reject  bool
+	}{
+		{"诚实的包含证明", "alice", big.NewInt(10), 8, nil, nil, false},
+		{"余额不对", "alice", big.NewInt(11), 8, nil, ErrSolvencyFailed, false},
+		{"客户ID不对", "bob", big.NewInt(10), 8, nil, ErrSolvencyFailed, false},
+		{"要求的范围不同", "alice", big.NewInt(10), 16, nil, ErrMalformedProof, false},
+		{"替换兄弟节点", "alice", big.NewInt(10), 8, func(inclusion *LiabilityInclusion) {
+			inclusion.Siblings[0] = tree.levels[0][2]
+		}, ErrSolvencyFailed, false},
+		{"缺少路径节点", "alice", big.NewInt(10), 8, func(inclusion *LiabilityInclusion) {
+			inclusion.Path = nil
+		}, ErrMalformedProof, false},
+		{"路径节点与子节点之和不符", "alice", big.NewInt(10), 8, func(inclusion *LiabilityInclusion) {
+			inclusion.Path[0] = tree.levels[1][1]
+		}, ErrSolvencyFailed, false},
+		{"路径节点的范围证明属于别的节点", "alice", big.NewInt(10), 8, func(inclusion *LiabilityInclusion) {
+			inclusion.Path[0].Proof = tree.levels[1][1].Proof
+		}, nil, true},
+		{"路径节点的范围证明更宽", "alice", big.NewInt(10), 8, func(inclusion *LiabilityInclusion) {
+			proof, err := ProveRange(ModeBulletproofs, G, H, GVector, HVector, big.NewInt(30), parentGamma, 16, solvencyTranscript())
+			if err != nil {
+				t.Fatal(err)
+			}
+			inclusion.Path[0].Proof = proof
+		}, ErrMalformedProof, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inclusion, err := tree.Inclusion("alice")
+			if err != nil {
+				t.Fatal(err)
+			}
+			inclusion.Siblings = append([]SumNode{}, inclusion.Siblings...)
+			inclusion.Path = append([]SumNode{}, inclusion.Path...)
+			if test.tamper != nil {
+				test.tamper(&inclusion)
+			}
+			err = VerifyLiability(G, H, GVector, HVector, test.n, tree.Root(), test.id, test.balance, inclusion)
+			switch {
+			case test.reject:
+				if !IsInvalidProof(err) {
+					t.Fatalf("err = %v，应为证明无效", err)
+				}
+			case !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil):
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+		})
+	}
+}
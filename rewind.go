@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+)
+
+//可回卷的范围证明（类似Grin/Mimblewimble）：经典模式中prover的随机数alpha,rho,tau1,tau2,sL,sR都由回卷密钥和写入陈述后的transcript状态确定地导出
+//持有密钥的接收者可以从证明中恢复：
+//v：l(x) = aL - z + sL*x，已知sL即可求出aL的每一位
+//gamma：taux = tau1*x + tau2*x^2 + z^2*gamma
+//消息：alpha = 导出的随机数 + 消息，mju = alpha + rho*x
+//对不知道密钥的人，这些随机数与均匀随机数不可区分，证明的格式和验证方式与普通证明完全相同
+//transcript状态覆盖V、n和之前写入的全部内容，同一个V在不同的transcript下重新证明时随机数也不同，不会在两组挑战下重用sL
+//transcript状态完全相同时得到的是同一个证明
+
+//嵌入消息的最大长度，第一个字节存长度，保证编码后的数小于N
+const maxRewindMessage = 31
+
+//由回卷密钥和transcript的副本导出经典模式的全部随机数，t必须已经写入了范围证明的陈述
+func rewindNonces(key []byte, t Transcript, n int64) classicNonces {
+	t.AppendBytes("rewind nonces", key)
+	nonces := classicNonces{
+		alpha: t.ChallengeScalar("alpha"),
+		rho:   t.ChallengeScalar("rho"),
+		tau1:  t.ChallengeScalar("tau1"),
+		tau2:  t.ChallengeScalar("tau2"),
+	}
+	for i := int64(0); i < n; i++ {
+		nonces.sL = append(nonces.sL, t.ChallengeScalar("sL"))
+		nonces.sR = append(nonces.sR, t.ChallengeScalar("sR"))
+	}
+	return nonces
+}
+
+//生成可回卷的范围证明，message最多31字节，会被嵌入到证明中
+func ProveRangeRewindable(G Point, H Point, GVector []Point, HVector []Point, v *big.Int, gamma *big.Int, n int64, key []byte, message []byte, transcript *Transcript) (RangeProof, error) {
+	if !IsValidRangeWidth(n) {
+		return RangeProof{}, ErrInvalidRangeWidth
+	}
+	if len(message) > maxRewindMessage {
+		return RangeProof{}, ErrMessageTooLong
+	}
+	if v.Sign() < 0 {
+		return RangeProof{}, ErrValueOutOfRange
+	}
+	embed := make([]byte, 1+maxRewindMessage)
+	embed[0] = byte(len(message))
+	copy(embed[1:], message)
+	derive := func(t Transcript) classicNonces {
+		nonces := rewindNonces(key, t, n)
+		nonces.alpha = addInP(nonces.alpha, big.NewInt(0).SetBytes(embed))
+		return nonces
+	}
+	return proveRange(ModeBulletproofs, G, H, GVector, HVector, v, gamma, n, derive, transcript)
+}
+
+//用回卷密钥从证明中恢复v,gamma和嵌入的消息，transcript的状态必须与生成证明时相同
+//密钥不对或证明不是用该密钥生成时返回ErrRewindFailed
+func RewindRange(G Point, H Point, V Point, proof RangeProof, key []byte, transcript *Transcript) (v *big.Int, gamma *big.Int, message []byte, err error) {
+	if proof.Mode != ModeBulletproofs {
+		return nil, nil, nil, fmt.Errorf("%w: 只有ModeBulletproofs的证明可以回卷", ErrUnknownMode)
+	}
+	n := proof.n
+	if !IsValidRangeWidth(n) {
+		return nil, nil, nil, ErrInvalidRangeWidth
+	}
+	if !IsOnCurve(V) {
+		return nil, nil, nil, fmt.Errorf("%w: 承诺V", ErrInvalidPoint)
+	}
+	if err := proof.checkClassic(); err != nil {
+		return nil, nil, nil, err
+	}
+	appendRangeStatement(transcript, proof.Mode, n, V)
+	nonces := rewindNonces(key, *transcript, n)
+	_, z, x := classicChallenges(transcript, proof)
+
+	//aL_i = l_i + z - sL_i*x，每一位必须是0或1
+	v = big.NewInt(0)
+	for i := n - 1; i >= 0; i-- {
+		bit := subInP(addInP(proof.lx[i], z), mulInP(nonces.sL[i], x))
+		if bit.Cmp(big.NewInt(1)) > 0 {
+			return nil, nil, nil, ErrRewindFailed
+		}
+		v.Lsh(v, 1)
+		v.Or(v, bit)
+	}
+
+	//gamma = (taux - tau1*x - tau2*x^2) / z^2
+	rest := subInP(subInP(proof.taux, mulInP(nonces.tau1, x)), mulInP(nonces.tau2, mulInP(x, x)))
+	gamma = mulInP(rest, inverseBig(mulInP(z, z)))
+	if !IsEqual(Commit(G, H, v.Bytes(), gamma.Bytes()), V) {
+		return nil, nil, nil, ErrRewindFailed
+	}
+
+	//消息 = mju - rho*x - alpha
+	embed := subInP(subInP(proof.mju, mulInP(nonces.rho, x)), nonces.alpha)
+	buf := ScalarBytes(embed)
+	length := int(buf[0])
+	if length > maxRewindMessage {
+		return nil, nil, nil, ErrRewindFailed
+	}
+	return v, gamma, buf[1 : 1+length], nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestRewindRange(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(64)
+	key := []byte("rewind key")
+	tests := []struct {
+		name    string
+		v       *big.Int
+		n       int64
+		message []byte
+		key     []byte
+		wantErr error
+	}{
+		{"带消息", big.NewInt(123456), 32, []byte("invoice #42"), key, nil},
+		{"空消息", big.NewInt(0), 8, nil, key, nil},
+		{"31字节消息", big.NewInt(255), 8, bytes.Repeat([]byte{0xff}, 31), key, nil},
+		{"密钥不对", big.NewInt(77), 16, []byte("x"), []byte("other key"), ErrRewindFailed},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gamma := GenerateRandomScalar()
+			proof, err := ProveRangeRewindable(G, H, GVector, HVector, test.v, gamma, test.n, key, test.message, newTranscript("rewind test"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			V := CommitCT(G, H, test.v.Bytes(), gamma.Bytes())
+			if err := VerifyRange(G, H, GVector, HVector, V, test.n, proof, newTranscript("rewind test")); err != nil {
+				t.Fatalf("可回卷的证明没有通过普通验证: %v", err)
+			}
+			v, gotGamma, message, err := RewindRange(G, H, V, proof, test.key, newTranscript("rewind test"))
+			if !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil) {
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if v.Cmp(test.v) != 0 || gotGamma.Cmp(gamma) != 0 || !bytes.Equal(message, test.message) {
+				t.Fatalf("恢复的结果不对: v = %v, message = %q", v, message)
+			}
+		})
+	}
+}
+
+func TestRewindRangeErrors(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(8)
+	key := []byte("rewind key")
+	gamma := GenerateRandomScalar()
+	if _, err := ProveRangeRewindable(G, H, GVector, HVector, big.NewInt(1), gamma, 8, key, make([]byte, 32), newTranscript("rewind test")); !errors.Is(err, ErrMessageTooLong) {
+		t.Fatalf("err = %v", err)
+	}
+	proof, err := ProveRange(ModeBulletproofsPlus, G, H, GVector, HVector, big.NewInt(1), gamma, 8, newTranscript("rewind test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	V := CommitCT(G, H, big.NewInt(1).Bytes(), gamma.Bytes())
+	if _, _, _, err := RewindRange(G, H, V, proof, key, newTranscript("rewind test")); !errors.Is(err, ErrUnknownMode) {
+		t.Fatalf("err = %v", err)
+	}
+	//用随机数生成的普通证明不能回卷
+	proof, err = ProveRange(ModeBulletproofs, G, H, GVector, HVector, big.NewInt(1), gamma, 8, newTranscript("rewind test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := RewindRange(G, H, V, proof, key, newTranscript("rewind test")); !errors.Is(err, ErrRewindFailed) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+//同一个V在不同的transcript下生成的两个证明不能重用随机数，否则两组挑战会暴露sL，进而暴露v和gamma
+func TestRewindNonceReuse(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(16)
+	key := []byte("rewind key")
+	v, gamma := big.NewInt(1000), GenerateRandomScalar()
+	V := CommitCT(G, H, v.Bytes(), gamma.Bytes())
+	var proofs []RangeProof
+	for _, label := range []string{"rewind test 1", "rewind test 2"} {
+		proof, err := ProveRangeRewindable(G, H, GVector, HVector, v, gamma, 16, key, []byte("memo"), newTranscript(label))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, gotGamma, _, err := RewindRange(G, H, V, proof, key, newTranscript(label))
+		if err != nil || got.Cmp(v) != 0 || gotGamma.Cmp(gamma) != 0 {
+			t.Fatalf("%s: v = %v, err = %v", label, got, err)
+		}
+		proofs = append(proofs, proof)
+	}
+	first, second := proofs[0], proofs[1]
+	for i, pair := range [][2]Point{{first.A, second.A}, {first.S, second.S}, {first.T1, second.T1}, {first.T2, second.T2}} {
+		if pair[0].x.Cmp(pair[1].x) == 0 && pair[0].y.Cmp(pair[1].y) == 0 {
+			t.Fatalf("两个证明的第%d个承诺相同", i)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"github.com/decred/dcrd/dcrec/secp256k1/v3"
+	"math/big"
+)
+
+//证明的序列化：点按33字节的压缩格式，Zp中的数按32字节大端，整数按8字节大端
+
+const (
+	pointSize  = 33
+	scalarSize = 32
+)
+
+//从压缩格式恢复点，33个0表示无穷远点
+func PointFromBytes(b []byte) (Point, error) {
+	if len(b) != pointSize {
+		return Point{}, fmt.Errorf("%w: 点的长度不是%d字节", ErrMalformedProof, pointSize)
+	}
+	infinity := true
+	for _, value := range b {
+		if value != 0 {
+			infinity = false
+			break
+		}
+	}
+	if infinity {
+		return Point{x: big.NewInt(0), y: big.NewInt(0)}, nil
+	}
+	pub, err := secp256k1.ParsePubKey(b)
+	if err != nil {
+		return Point{}, fmt.Errorf("%w: %v", ErrInvalidPoint, err)
+	}
+	return Point{x: pub.X(), y: pub.Y()}, nil
+}
+
+//按32字节编码Zp中的数
+func ScalarBytes(s *big.Int) []byte {
+	buf := make([]byte, scalarSize)
+	big.NewInt(0).Mod(s, curve.N).FillBytes(buf)
+	return buf
+}
+
+//依次写入证明的各个字段
+type proofWriter struct {
+	buf []byte
+}
+
+func (w *proofWriter) writeByte(b byte) {
+	w.buf = append(w.buf, b)
+}
+
+func (w *proofWriter) writeInt(i int64) {
+	w.buf = append(w.buf, Int64ToBytes(i)...)
+}
+
+func (w *proofWriter) writePoint(p Point) {
+	w.buf = append(w.buf, p.Bytes()...)
+}
+
+func (w *proofWriter) writeScalar(s *big.Int) {
+	w.buf = append(w.buf, ScalarBytes(s)...)
+}
+
+func (w *proofWriter) writeBytes(b []byte) {
+	w.writeInt(int64(len(b)))
+	w.buf = append(w.buf, b...)
+}
+
+func (w *proofWriter) writePoints(points []Point) {
+	w.writeInt(int64(len(points)))
+	for _, p := range points {
+		w.writePoint(p)
+	}
+}
+
+func (w *proofWriter) writeScalars(scalars []*big.Int) {
+	w.writeInt(int64(len(scalars)))
+	for _, s := range scalars {
+		w.writeScalar(s)
+	}
+}
+
+//按写入的顺序读出各个字段，第一次出错后的读取都返回零值，最后统一检查err
+type proofReader struct {
+	buf []byte
+	err error
+}
+
+func (r *proofReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || len(r.buf) < n {
+		r.err = fmt.Errorf("%w: 数据长度不足", ErrMalformedProof)
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *proofReader) readByte() byte {
+	b := r.next(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (r *proofReader) readInt() int64 {
+	b := r.next(8)
+	if b == nil {
+		return 0
+	}
+	return BytesToInt64(b)
+}
+
+func (r *proofReader) readPoint() Point {
+	b := r.next(pointSize)
+	if b == nil {
+		return Point{}
+	}
+	p, err := PointFromBytes(b)
+	if err != nil {
+		r.err = err
+	}
+	return p
+}
+
+func (r *proofReader) readScalar() *big.Int {
+	b := r.next(scalarSize)
+	if b == nil {
+		return nil
+	}
+	s := big.NewInt(0).SetBytes(b)
+	if s.Cmp(curve.N) >= 0 {
+		r.err = fmt.Errorf("%w: 标量超出范围", ErrMalformedProof)
+	}
+	return s
+}
+
+//读取长度前缀，长度不能超过剩余数据能容纳的元素个数
+func (r *proofReader) readLength(elemSize int) int {
+	l := r.readInt()
+	if r.err == nil && (l < 0 || l > int64(len(r.buf)/elemSize)) {
+		r.err = fmt.Errorf("%w: 长度前缀不合法", ErrMalformedProof)
+	}
+	if r.err != nil {
+		return 0
+	}
+	return int(l)
+}
+
+func (r *proofReader) readBytes() []byte {
+	return append([]byte{}, r.next(r.readLength(1))...)
+}
+
+func (r *proofReader) readPoints() []Point {
+	var points []Point
+	for i := r.readLength(pointSize); i > 0; i-- {
+		points = append(points, r.readPoint())
+	}
+	return points
+}
+
+func (r *proofReader) readScalars() []*big.Int {
+	var scalars []*big.Int
+	for i := r.readLength(scalarSize); i > 0; i-- {
+		scalars = append(scalars, r.readScalar())
+	}
+	return scalars
+}
+
+//读取结束时检查是否有多余的数据
+func (r *proofReader) finish() error {
+	if r.err == nil && len(r.buf) != 0 {
+		r.err = fmt.Errorf("%w: 存在多余的数据", ErrMalformedProof)
+	}
+	return r.err
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"github.com/decred/dcrd/dcrec/secp256k1/v3"
+	"math/big"
+)
+
+//协议所处的阶段，prover和verifier按相同的顺序推进
+type sessionPhase int
+
+const (
+	phaseInit sessionPhase = iota //刚创建，尚未交换任何消息
+	phaseAS                       //A,S已发送/接收
+	phaseYZ                       //y,z已发送/接收
+	phaseT                        //T1,T2已发送/接收
+	phaseX                        //x已发送/接收
+	phaseDone                     //零知识证明已生成/验证，会话结束
+)
+
+func (phase sessionPhase) String() string {
+	switch phase {
+	case phaseInit:
+		return "初始化"
+	case phaseAS:
+		return "承诺A,S"
+	case phaseYZ:
+		return "挑战y,z"
+	case phaseT:
+		return "承诺T1,T2"
+	case phaseX:
+		return "挑战x"
+	case phaseDone:
+		return "已结束"
+	}
+	return "未知阶段"
+}
+
+//检查当前是否处于expected阶段
+func checkPhase(current sessionPhase, expected sessionPhase) error {
+	if current == phaseDone {
+		return ErrSessionFinished
+	}
+	if current != expected {
+		return fmt.Errorf("%w: 当前处于%v阶段，需要处于%v阶段", ErrOutOfOrder, current, expected)
+	}
+	return nil
+}
+
+//prover一方的会话，每次证明使用一个新的会话
+type ProverSession struct {
+	prover Prover
+	phase  sessionPhase
+}
+
+//verifier一方的会话，验证结束后不能再次使用
+type VerifierSession struct {
+	verifier Verifier
+	phase    sessionPhase
+}
+
+func (session *ProverSession) New(G Point, H Point, GVector []Point, HVector []Point, v *big.Int, n int64, curve secp256k1.KoblitzCurve) error {
+	session.prover = Prover{}
+	session.phase = phaseInit
+	return session.prover.New(G, H, GVector, HVector, v, n, curve)
+}
+
+//第一步：生成并发送承诺A,S
+func (session *ProverSession) CommitAS() (Point, Point, error) {
+	if err := checkPhase(session.phase, phaseInit); err != nil {
+		return Point{}, Point{}, err
+	}
+	A, S, err := session.prover.GetAS()
+	if err != nil {
+		return Point{}, Point{}, err
+	}
+	session.phase = phaseAS
+	return A, S, nil
+}
+
+//第二步：接收verifier的挑战y,z
+func (session *ProverSession) ReceiveYZ(y byte, z byte) error {
+	if err := checkPhase(session.phase, phaseAS); err != nil {
+		return err
+	}
+	session.prover.y = y
+	session.prover.z = z
+	session.phase = phaseYZ
+	return nil
+}
+
+//第三步：生成并发送承诺T1,T2
+func (session *ProverSession) CommitT() (Point, Point, error) {
+	if err := checkPhase(session.phase, phaseYZ); err != nil {
+		return Point{}, Point{}, err
+	}
+	T1, T2, err := session.prover.GetT()
+	if err != nil {
+		return Point{}, Point{}, err
+	}
+	session.phase = phaseT
+	return T1, T2, nil
+}
+
+//第四步：接收verifier的挑战x
+func (session *ProverSession) ReceiveX(x int) error {
+	if err := checkPhase(session.phase, phaseT); err != nil {
+		return err
+	}
+	session.prover.x = x
+	session.phase = phaseX
+	return nil
+}
+
+//第五步：生成零知识证明
+//Prover回应挑战x后会清除本次使用的随机数，会话随之结束，不能再对其他x作答
+func (session *ProverSession) Prove() (ProverZKP, error) {
+	if err := checkPhase(session.phase, phaseX); err != nil {
+		return ProverZKP{}, err
+	}
+	proverZKP, err := session.prover.GetProverZKP()
+	if err != nil {
+		return ProverZKP{}, err
+	}
+	session.phase = phaseDone
+	return proverZKP, nil
+}
+
+func (session *VerifierSession) New(G Point, H Point, GVector []Point, HVector []Point, n int64, curve secp256k1.KoblitzCurve) error {
+	session.verifier = Verifier{}
+	session.phase = phaseInit
+	return session.verifier.New(G, H, GVector, HVector, n, curve)
+}
+
+//第一步：接收prover的承诺A,S
+func (session *VerifierSession) ReceiveAS(A Point, S Point) error {
+	if err := checkPhase(session.phase, phaseInit); err != nil {
+		return err
+	}
+	if err := session.verifier.GetAS(A, S); err != nil {
+		return err
+	}
+	session.phase = phaseAS
+	return nil
+}
+
+//第二步：生成挑战y,z
+func (session *VerifierSession) ChallengeYZ() (byte, byte, error) {
+	if err := checkPhase(session.phase, phaseAS); err != nil {
+		return 0, 0, err
+	}
+	session.verifier.GenerateYZ()
+	session.phase = phaseYZ
+	return session.verifier.y, session.verifier.z, nil
+}
+
+//第三步：接收prover的承诺T1,T2
+func (session *VerifierSession) ReceiveT(T1 Point, T2 Point) error {
+	if err := checkPhase(session.phase, phaseYZ); err != nil {
+		return err
+	}
+	if err := session.verifier.GetT(T1, T2); err != nil {
+		return err
+	}
+	session.phase = phaseT
+	return nil
+}
+
+//第四步：生成挑战x
+func (session *VerifierSession) ChallengeX() (int, error) {
+	if err := checkPhase(session.phase, phaseT); err != nil {
+		return 0, err
+	}
+	session.verifier.GenerateX()
+	session.phase = phaseX
+	return session.verifier.x, nil
+}
+
+//第五步：验证零知识证明，无论结果如何会话都随之结束
+func (session *VerifierSession) Verify(proverZKP ProverZKP) error {
+	if err := checkPhase(session.phase, phaseX); err != nil {
+		return err
+	}
+	session.phase = phaseDone
+	session.verifier.proverZKP = proverZKP
+	return session.verifier.VerifyZKP()
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func newSessions(t *testing.T, v *big.Int, n int64) (*ProverSession, *VerifierSession) {
+	G, H, GVector, HVector := testGenerators(n)
+	var prover ProverSession
+	var verifier VerifierSession
+	if err := prover.New(G, H, GVector, HVector, v, n, *curve); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifier.New(G, H, GVector, HVector, n, *curve); err != nil {
+		t.Fatal(err)
+	}
+	return &prover, &verifier
+}
+
+//按正确的顺序完成一次会话，tamper在证明发送给verifier之前修改它
+func runSessions(t *testing.T, prover *ProverSession, verifier *VerifierSession, tamper func(proof *ProverZKP)) (ProverZKP, error) {
+	A, S, err := prover.CommitAS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifier.ReceiveAS(A, S); err != nil {
+		t.Fatal(err)
+	}
+	y, z, err := verifier.ChallengeYZ()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := prover.ReceiveYZ(y, z); err != nil {
+		t.Fatal(err)
+	}
+	T1, T2, err := prover.CommitT()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifier.ReceiveT(T1, T2); err != nil {
+		t.Fatal(err)
+	}
+	x, err := verifier.ChallengeX()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := prover.ReceiveX(x); err != nil {
+		t.Fatal(err)
+	}
+	proverZKP, err := prover.Prove()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tamper != nil {
+		tamper(&proverZKP)
+	}
+	return proverZKP, verifier.Verify(proverZKP)
+}
+
+func TestSessionHonest(t *testing.T) {
+	for _, v := range []int64{0, 1, 100, 255} {
+		prover, verifier := newSessions(t, big.NewInt(v), 8)
+		if _, err := runSessions(t, prover, verifier, nil); err != nil {
+			t.Fatalf("v = %d: %v", v, err)
+		}
+	}
+}
+
+func TestSessionTampered(t *testing.T) {
+	prover, verifier := newSessions(t, big.NewInt(5), 8)
+	_, err := runSessions(t, prover, verifier, func(proof *ProverZKP) {
+		proof.rx[1] = addInP(proof.rx[1], big.NewInt(1))
+	})
+	if !IsInvalidProof(err) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestSessionOutOfOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		step func(prover *ProverSession, verifier *VerifierSession) error
+	}{
+		{"先接收y,z", func(prover *ProverSession, verifier *VerifierSession) error {
+			return prover.ReceiveYZ(1, 1)
+		}},
+		{"先生成T1,T2", func(prover *ProverSession, verifier *VerifierSession) error {
+			_, _, err := prover.CommitT()
+			return err
+		}},
+		{"重复生成A,S", func(prover *ProverSession, verifier *VerifierSession) error {
+			prover.CommitAS()
+			_, _, err := prover.CommitAS()
+			return err
+		}},
+		{"没有x就生成证明", func(prover *ProverSession, verifier *VerifierSession) error {
+			_, err := prover.Prove()
+			return err
+		}},
+		{"verifier先发送y,z", func(prover *ProverSession, verifier *VerifierSession) error {
+			_, _, err := verifier.ChallengeYZ()
+			return err
+		}},
+		{"verifier先发送x", func(prover *ProverSession, verifier *VerifierSession) error {
+			_, err := verifier.ChallengeX()
+			return err
+		}},
+		{"verifier提前验证", func(prover *ProverSession, verifier *VerifierSession) error {
+			return verifier.Verify(ProverZKP{})
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			prover, verifier := newSessions(t, big.NewInt(5), 8)
+			if err := test.step(prover, verifier); !errors.Is(err, ErrOutOfOrder) {
+				t.Fatalf("err = %v，应为ErrOutOfOrder", err)
+			}
+		})
+	}
+}
+
+func TestSessionFinished(t *testing.T) {
+	prover, verifier := newSessions(t, big.NewInt(5), 8)
+	proverZKP, err := runSessions(t, prover, verifier, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := prover.Prove(); !errors.Is(err, ErrSessionFinished) {
+		t.Fatalf("重复生成证明: err = %v", err)
+	}
+	if err := prover.ReceiveX(1); !errors.Is(err, ErrSessionFinished) {
+		t.Fatalf("结束后接收x: err = %v", err)
+	}
+	if err := verifier.Verify(proverZKP); !errors.Is(err, ErrSessionFinished) {
+		t.Fatalf("重复验证: err = %v", err)
+	}
+	if prover.prover.aL != nil || prover.prover.sL != nil {
+		t.Fatal("回应挑战后没有清除秘密矢量")
+	}
+}
@@ -0,0 +1,471 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+)
+
+//线性sigma协议框架
+//陈述是若干线性关系Y_j = Σ x_(w)*B_(j,w)，prover证明知道满足所有关系的秘密x
+//协议：prover发送R_j = Σ k_w*B_(j,w)，收到挑战c后回复s_w = k_w + c*x_w，verifier检查Σ s_w*B_(j,w) = R_j + c*Y_j
+//AND组合：所有子陈述使用同一个挑战；OR组合（CDS）：子挑战之和等于c，不知道秘密的分支用模拟的对话
+//Prove/Verify用transcript做Fiat-Shamir变换，SigmaProver和VerifySigma是交互形式
+
+type sigmaKind byte
+
+const (
+	sigmaLinear sigmaKind = 1
+	sigmaAnd    sigmaKind = 2
+	sigmaOr     sigmaKind = 3
+)
+
+//线性关系中的一项：x[Witness]*Base
+type SigmaTerm struct {
+	Witness int
+	Base    Point
+}
+
+//Target = Σ Terms
+type SigmaEquation struct {
+	Target Point
+	Terms  []SigmaTerm
+}
+
+type SigmaStatement struct {
+	kind      sigmaKind
+	witnesses int
+	equations []SigmaEquation
+	children  []SigmaStatement
+}
+
+//秘密：线性陈述用Values；AND对应每个子陈述一个Children；OR只需要第Known个分支的秘密，放在Children[0]
+type SigmaWitness struct {
+	Values   []*big.Int
+	Children []SigmaWitness
+	Known    int
+}
+
+type SigmaProof struct {
+	Commitments []Point
+	Responses   []*big.Int
+	Challenges  []*big.Int //OR的各分支挑战
+	Children    []SigmaProof
+}
+
+//prover在发送承诺和收到挑战之间保存的随机数，以及OR中模拟分支的挑战和完整对话
+//模拟分支的挑战和回复只能在Respond时和真实分支一起发出，否则verifier能从第一条消息看出哪个分支是真的
+type sigmaState struct {
+	nonces     []*big.Int
+	children   []sigmaState
+	challenges []*big.Int
+	simulated  []SigmaProof
+}
+
+//由witnesses个秘密构成的线性关系
+func LinearStatement(witnesses int, equations ...SigmaEquation) SigmaStatement {
+	return SigmaStatement{kind: sigmaLinear, witnesses: witnesses, equations: equations}
+}
+
+func AndStatement(children ...SigmaStatement) SigmaStatement {
+	return SigmaStatement{kind: sigmaAnd, children: children}
+}
+
+func OrStatement(children ...SigmaStatement) SigmaStatement {
+	return SigmaStatement{kind: sigmaOr, children: children}
+}
+
+func LinearWitness(values ...*big.Int) SigmaWitness {
+	return SigmaWitness{Values: values}
+}
+
+func AndWitness(children ...SigmaWitness) SigmaWitness {
+	return SigmaWitness{Children: children}
+}
+
+//OR陈述的秘密：第known个分支成立，witness是该分支的秘密
+func OrWitness(known int, witness SigmaWitness) SigmaWitness {
+	return SigmaWitness{Children: []SigmaWitness{witness}, Known: known}
+}
+
+//常用陈述：知道V = v*G + gamma*H的打开(v,gamma)
+func OpeningStatement(G Point, H Point, V Point) SigmaStatement {
+	return LinearStatement(2, SigmaEquation{Target: V, Terms: []SigmaTerm{{0, G}, {1, H}}})
+}
+
+//常用陈述：V是对公开值v的承诺，即知道gamma使V - v*G = gamma*H
+func PublicOpeningStatement(G Point, H Point, V Point, v *big.Int) SigmaStatement {
+	return LinearStatement(1, SigmaEquation{Target: openingTarget(G, V, v), Terms: []SigmaTerm{{0, H}}})
+}
+
+//常用陈述：log_G(A) = log_H(B)
+func EqualityStatement(G Point, H Point, A Point, B Point) SigmaStatement {
+	return LinearStatement(1,
+		SigmaEquation{Target: A, Terms: []SigmaTerm{{0, G}}},
+		SigmaEquation{Target: B, Terms: []SigmaTerm{{0, H}}})
+}
+
+//常用陈述：V中的值属于集合set，证明大小与集合大小成正比，集合较大时用ProveMembership
+func SetMembershipStatement(G Point, H Point, V Point, set []*big.Int) SigmaStatement {
+	var children []SigmaStatement
+	for _, value := range set {
+		children = append(children, PublicOpeningStatement(G, H, V, value))
+	}
+	return OrStatement(children...)
+}
+
+//检查陈述本身的结构
+func (statement SigmaStatement) check() error {
+	switch statement.kind {
+	case sigmaLinear:
+		if statement.witnesses < 1 || len(statement.equations) == 0 {
+			return fmt.Errorf("%w: 线性陈述没有秘密或方程", ErrMalformedStatement)
+		}
+		for _, equation := range statement.equations {
+			if !IsOnCurve(equation.Target) {
+				return fmt.Errorf("%w: 方程的目标点", ErrInvalidPoint)
+			}
+			for _, term := range equation.Terms {
+				if term.Witness < 0 || term.Witness >= statement.witnesses {
+					return fmt.Errorf("%w: 秘密的下标超出范围", ErrMalformedStatement)
+				}
+				if !IsOnCurve(term.Base) {
+					return fmt.Errorf("%w: 方程的生成元", ErrInvalidPoint)
+				}
+			}
+		}
+		return nil
+	case sigmaAnd, sigmaOr:
+		if len(statement.children) == 0 {
+			return fmt.Errorf("%w: 组合陈述没有子陈述", ErrMalformedStatement)
+		}
+		for _, child := range statement.children {
+			if err := child.check(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: 未知的陈述类型", ErrMalformedStatement)
+}
+
+//写入陈述的结构和所有公开的点
+func (statement SigmaStatement) appendTo(transcript *Transcript) {
+	transcript.AppendBytes("sigma", []byte{byte(statement.kind)})
+	switch statement.kind {
+	case sigmaLinear:
+		transcript.AppendInt("witnesses", int64(statement.witnesses))
+		transcript.AppendInt("equations", int64(len(statement.equations)))
+		for _, equation := range statement.equations {
+			transcript.AppendPoint("target", equation.Target)
+			transcript.AppendInt("terms", int64(len(equation.Terms)))
+			for _, term := range equation.Terms {
+				transcript.AppendInt("witness", int64(term.Witness))
+				transcript.AppendPoint("base", term.Base)
+			}
+		}
+	default:
+		transcript.AppendInt("children", int64(len(statement.children)))
+		for _, child := range statement.children {
+			child.appendTo(transcript)
+		}
+	}
+}
+
+//只保留承诺R_j的第一条消息
+func (proof SigmaProof) commitmentsOnly() SigmaProof {
+	first := SigmaProof{Commitments: append([]Point{}, proof.Commitments...)}
+	for _, child := range proof.Children {
+		first.Children = append(first.Children, child.commitmentsOnly())
+	}
+	return first
+}
+
+//按深度优先的顺序写入所有承诺R_j
+func (proof SigmaProof) appendCommitments(transcript *Transcript) {
+	for _, R := range proof.Commitments {
+		transcript.AppendPoint("R", R)
+	}
+	for _, child := range proof.Children {
+		child.appendCommitments(transcript)
+	}
+}
+
+//计算Σ x_w*B_w，x是秘密时使用常数时间运算
+func (equation SigmaEquation) evaluate(x []*big.Int) Point {
+	var bases []Point
+	var scalars []*big.Int
+	for _, term := range equation.Terms {
+		bases = append(bases, term.Base)
+		scalars = append(scalars, modN(x[term.Witness]))
+	}
+	return CommitSingleVectorCT(bases, scalars)
+}
+
+//第一步：生成承诺；OR中不成立的分支此时已经用随机挑战模拟完成，返回的证明只包含承诺
+func (statement SigmaStatement) commit(witness SigmaWitness) (SigmaProof, sigmaState, error) {
+	var proof SigmaProof
+	var state sigmaState
+	switch statement.kind {
+	case sigmaLinear:
+		if len(witness.Values) != statement.witnesses {
+			return proof, state, fmt.Errorf("%w: 秘密的个数不对", ErrSigmaWitness)
+		}
+		for _, equation := range statement.equations {
+			if !IsEqual(equation.evaluate(witness.Values), equation.Target) {
+				return proof, state, ErrSigmaWitness
+			}
+		}
+		state.nonces = GenerateRandomVector(int64(statement.witnesses))
+		for _, equation := range statement.equations {
+			proof.Commitments = append(proof.Commitments, equation.evaluate(state.nonces))
+		}
+	case sigmaAnd:
+		if len(witness.Children) != len(statement.children) {
+			return proof, state, fmt.Errorf("%w: AND的子秘密个数不对", ErrSigmaWitness)
+		}
+		for i, child := range statement.children {
+			childProof, childState, err := child.commit(witness.Children[i])
+			if err != nil {
+				return proof, state, err
+			}
+			proof.Children = append(proof.Children, childProof)
+			state.children = append(state.children, childState)
+		}
+	case sigmaOr:
+		if len(witness.Children) != 1 || witness.Known < 0 || witness.Known >= len(statement.children) {
+			return proof, state, fmt.Errorf("%w: OR的秘密格式不对", ErrSigmaWitness)
+		}
+		state.challenges = make([]*big.Int, len(statement.children))
+		state.simulated = make([]SigmaProof, len(statement.children))
+		state.children = make([]sigmaState, len(statement.children))
+		for i, child := range statement.children {
+			if i == witness.Known {
+				childProof, childState, err := child.commit(witness.Children[0])
+				if err != nil {
+					return proof, state, err
+				}
+				proof.Children = append(proof.Children, childProof)
+				state.children[i] = childState
+				continue
+			}
+			state.challenges[i] = GenerateRandomScalar()
+			state.simulated[i] = child.simulate(state.challenges[i])
+			proof.Children = append(proof.Children, state.simulated[i].commitmentsOnly())
+		}
+	}
+	return proof, state, nil
+}
+
+//第二步：收到挑战c后生成回复，OR中模拟分支的挑战和回复此时才填入证明
+func (statement SigmaStatement) respond(witness SigmaWitness, state sigmaState, proof *SigmaProof, c *big.Int) {
+	switch statement.kind {
+	case sigmaLinear:
+		for w, k := range state.nonces {
+			proof.Responses = append(proof.Responses, addInP(k, mulInP(c, modN(witness.Values[w]))))
+			k.SetInt64(0)
+		}
+	case sigmaAnd:
+		for i, child := range statement.children {
+			child.respond(witness.Children[i], state.children[i], &proof.Children[i], c)
+		}
+	case sigmaOr:
+		known := witness.Known
+		rest := c
+		proof.Challenges = make([]*big.Int, len(statement.children))
+		for i, challenge := range state.challenges {
+			if i != known {
+				rest = subInP(rest, challenge)
+				proof.Challenges[i] = challenge
+				proof.Children[i] = state.simulated[i]
+			}
+		}
+		proof.Challenges[known] = rest
+		statement.children[known].respond(witness.Children[0], state.children[known], &proof.Children[known], rest)
+	}
+}
+
+//对给定的挑战c模拟一次对话：先选回复，再反推承诺R_j = Σ s_w*B_(j,w) - c*Y_j
+func (statement SigmaStatement) simulate(c *big.Int) SigmaProof {
+	var proof SigmaProof
+	switch statement.kind {
+	case sigmaLinear:
+		proof.Responses = GenerateRandomVector(int64(statement.witnesses))
+		for _, equation := range statement.equations {
+			R := MultiCommit(equation.evaluate(proof.Responses), CommitSingle(equation.Target, negBig(c).Bytes()))
+			proof.Commitments = append(proof.Commitments, R)
+		}
+	case sigmaAnd:
+		for _, child := range statement.children {
+			proof.Children = append(proof.Children, child.simulate(c))
+		}
+	case sigmaOr:
+		rest := c
+		for i, child := range statement.children {
+			challenge := rest
+			if i < len(statement.children)-1 {
+				challenge = GenerateRandomScalar()
+				rest = subInP(rest, challenge)
+			}
+			proof.Challenges = append(proof.Challenges, challenge)
+			proof.Children = append(proof.Children, child.simulate(challenge))
+		}
+	}
+	return proof
+}
+
+func validScalar(value *big.Int) bool {
+	return value != nil && value.Sign() >= 0 && value.Cmp(curve.N) < 0
+}
+
+//检查证明的结构与陈述一致
+func (statement SigmaStatement) checkProof(proof SigmaProof) error {
+	switch statement.kind {
+	case sigmaLinear:
+		if len(proof.Commitments) != len(statement.equations) || len(proof.Responses) != statement.witnesses || len(proof.Children) != 0 {
+			return fmt.Errorf("%w: 证明与线性陈述的结构不符", ErrMalformedProof)
+		}
+		for _, R := range proof.Commitments {
+			if !IsOnCurve(R) {
+				return fmt.Errorf("%w: %v", ErrMalformedProof, ErrInvalidPoint)
+			}
+		}
+		for _, s := range proof.Responses {
+			if !validScalar(s) {
+				return fmt.Errorf("%w: 标量超出范围", ErrMalformedProof)
+			}
+		}
+		return nil
+	default:
+		if len(proof.Children) != len(statement.children) || len(proof.Commitments) != 0 || len(proof.Responses) != 0 {
+			return fmt.Errorf("%w: 证明与组合陈述的结构不符", ErrMalformedProof)
+		}
+		if statement.kind == sigmaOr && len(proof.Challenges) != len(statement.children) {
+			return fmt.Errorf("%w: OR的子挑战个数不对", ErrMalformedProof)
+		}
+		for _, challenge := range proof.Challenges {
+			if statement.kind != sigmaOr || !validScalar(challenge) {
+				return fmt.Errorf("%w: 子挑战格式错误", ErrMalformedProof)
+			}
+		}
+		for i, child := range statement.children {
+			if err := child.checkProof(proof.Children[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+//在挑战c下检查验证等式，调用前已经检查过结构
+func (statement SigmaStatement) verify(proof SigmaProof, c *big.Int) error {
+	switch statement.kind {
+	case sigmaLinear:
+		for j, equation := range statement.equations {
+			left := Point{x: big.NewInt(0), y: big.NewInt(0)}
+			for _, term := range equation.Terms {
+				left = MultiCommit(left, CommitSingle(term.Base, proof.Responses[term.Witness].Bytes()))
+			}
+			right := MultiCommit(proof.Commitments[j], CommitSingle(equation.Target, c.Bytes()))
+			if !IsEqual(left, right) {
+				return ErrSigmaFailed
+			}
+		}
+	case sigmaAnd:
+		for i, child := range statement.children {
+			if err := child.verify(proof.Children[i], c); err != nil {
+				return err
+			}
+		}
+	case sigmaOr:
+		sum := big.NewInt(0)
+		for i, child := range statement.children {
+			sum = addInP(sum, proof.Challenges[i])
+			if err := child.verify(proof.Children[i], proof.Challenges[i]); err != nil {
+				return err
+			}
+		}
+		if sum.Cmp(modN(c)) != 0 {
+			return ErrSigmaFailed
+		}
+	}
+	return nil
+}
+
+//交互形式的prover
+type SigmaProver struct {
+	statement SigmaStatement
+	witness   SigmaWitness
+	proof     SigmaProof
+	state     sigmaState
+	committed bool
+}
+
+func (prover *SigmaProver) New(statement SigmaStatement, witness SigmaWitness) error {
+	if err := statement.check(); err != nil {
+		return err
+	}
+	prover.statement, prover.witness = statement, witness
+	prover.committed = false
+	return nil
+}
+
+//第一步：返回只包含承诺的证明，发送给verifier
+//OR中模拟分支的挑战和回复留在prover中，第一条消息与知道哪个分支的秘密无关
+func (prover *SigmaProver) Commit() (SigmaProof, error) {
+	proof, state, err := prover.statement.commit(prover.witness)
+	if err != nil {
+		return SigmaProof{}, err
+	}
+	prover.proof, prover.state, prover.committed = proof, state, true
+	return proof.commitmentsOnly(), nil
+}
+
+//第二步：收到挑战后返回完整的证明，随机数只能使用一次
+func (prover *SigmaProver) Respond(c *big.Int) (SigmaProof, error) {
+	if !prover.committed {
+		return SigmaProof{}, ErrMissingState
+	}
+	prover.committed = false
+	prover.statement.respond(prover.witness, prover.state, &prover.proof, modN(c))
+	prover.state = sigmaState{}
+	return prover.proof, nil
+}
+
+//交互形式的验证：c是verifier发出的挑战
+func VerifySigma(statement SigmaStatement, proof SigmaProof, c *big.Int) error {
+	if err := statement.check(); err != nil {
+		return err
+	}
+	if err := statement.checkProof(proof); err != nil {
+		return err
+	}
+	return statement.verify(proof, c)
+}
+
+//Fiat-Shamir变换后的证明：挑战由陈述和所有承诺导出
+func ProveSigma(statement SigmaStatement, witness SigmaWitness, transcript *Transcript) (SigmaProof, error) {
+	var prover SigmaProver
+	if err := prover.New(statement, witness); err != nil {
+		return SigmaProof{}, err
+	}
+	proof, err := prover.Commit()
+	if err != nil {
+		return SigmaProof{}, err
+	}
+	statement.appendTo(transcript)
+	proof.appendCommitments(transcript)
+	return prover.Respond(transcript.ChallengeScalar("c"))
+}
+
+//验证非交互证明
+func VerifySigmaNI(statement SigmaStatement, proof SigmaProof, transcript *Transcript) error {
+	if err := statement.check(); err != nil {
+		return err
+	}
+	if err := statement.checkProof(proof); err != nil {
+		return err
+	}
+	statement.appendTo(transcript)
+	proof.appendCommitments(transcript)
+	return statement.verify(proof, transcript.ChallengeScalar("c"))
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+//检查第一条消息中没有任何挑战和回复
+func checkFirstMessage(t *testing.T, proof SigmaProof) {
+	if len(proof.Challenges) != 0 || len(proof.Responses) != 0 {
+		t.Fatalf("第一条消息中有%d个挑战、%d个回复", len(proof.Challenges), len(proof.Responses))
+	}
+	for _, child := range proof.Children {
+		checkFirstMessage(t, child)
+	}
+}
+
+func TestSigmaNI(t *testing.T) {
+	G, H := GeneratePoint(), GeneratePoint()
+	v, gamma := big.NewInt(3), GenerateRandomScalar()
+	V := CommitCT(G, H, v.Bytes(), gamma.Bytes())
+	x := GenerateRandomScalar()
+	A, B := CommitSingle(G, x.Bytes()), CommitSingle(H, x.Bytes())
+	set := []*big.Int{big.NewInt(1), big.NewInt(3), big.NewInt(5)}
+
+	opening := OpeningStatement(G, H, V)
+	equality := EqualityStatement(G, H, A, B)
+	membership := SetMembershipStatement(G, H, V, set)
+	tests := []struct {
+		name      string
+		statement SigmaStatement
+		witness   SigmaWitness
+		verifyAs  SigmaStatement
+		tamper    func(proof *SigmaProof)
+		wantErr   error
+	}{
+		{"打开", opening, LinearWitness(v, gamma), opening, nil, nil},
+		{"离散对数相等", equality, LinearWitness(x), equality, nil, nil},
+		{"AND", AndStatement(opening, equality), AndWitness(LinearWitness(v, gamma), LinearWitness(x)), AndStatement(opening, equality), nil, nil},
+		{"集合成员", membership, OrWitness(1, LinearWitness(gamma)), membership, nil, nil},
+		{"OR中嵌套AND", OrStatement(equality, AndStatement(opening, equality)), OrWitness(1, AndWitness(LinearWitness(v, gamma), LinearWitness(x))), OrStatement(equality, AndStatement(opening, equality)), nil, nil},
+		{"篡改回复", opening, LinearWitness(v, gamma), opening, func(proof *SigmaProof) {
+			proof.Responses[0] = addInP(proof.Responses[0], big.NewInt(1))
+		}, ErrSigmaFailed},
+		{"篡改OR的子挑战", membership, OrWitness(1, LinearWitness(gamma)), membership, func(proof *SigmaProof) {
+			proof.Challenges[0] = addInP(proof.Challenges[0], big.NewInt(1))
+			proof.Challenges[1] = subInP(proof.Challenges[1], big.NewInt(1))
+		}, ErrSigmaFailed},
+		{"缺少OR的子挑战", membership, OrWitness(1, LinearWitness(gamma)), membership, func(proof *SigmaProof) {
+			proof.Challenges = proof.Challenges[:2]
+		}, ErrMalformedProof},
+		{"换成别的集合", membership, OrWitness(1, LinearWitness(gamma)), SetMembershipStatement(G, H, V, []*big.Int{big.NewInt(2), big.NewInt(3), big.NewInt(5)}), nil, ErrSigmaFailed},
+		{"回复超出Zp", opening, LinearWitness(v, gamma), opening, func(proof *SigmaProof) {
+			proof.Responses[1] = big.NewInt(0).Set(curve.N)
+		}, ErrMalformedProof},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			proof, err := ProveSigma(test.statement, test.witness, newTranscript("sigma test"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.tamper != nil {
+				test.tamper(&proof)
+			}
+			err = VerifySigmaNI(test.verifyAs, proof, newTranscript("sigma test"))
+			if !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil) {
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+		})
+	}
+
+	if _, err := ProveSigma(membership, OrWitness(0, LinearWitness(gamma)), newTranscript("sigma test")); !errors.Is(err, ErrSigmaWitness) {
+		t.Fatalf("秘密不满足分支: err = %v", err)
+	}
+	if _, err := ProveSigma(opening, LinearWitness(v), newTranscript("sigma test")); !errors.Is(err, ErrSigmaWitness) {
+		t.Fatalf("秘密个数不对: err = %v", err)
+	}
+	if _, err := ProveSigma(OrStatement(), OrWitness(0, LinearWitness(x)), newTranscript("sigma test")); !errors.Is(err, ErrMalformedStatement) {
+		t.Fatalf("空的OR: err = %v", err)
+	}
+}
+
+func TestSigmaInteractive(t *testing.T) {
+	G, H := GeneratePoint(), GeneratePoint()
+	gamma := GenerateRandomScalar()
+	set := []*big.Int{big.NewInt(1), big.NewInt(3), big.NewInt(5)}
+	for known, v := range set {
+		t.Run(v.String(), func(t *testing.T) {
+			V := CommitCT(G, H, v.Bytes(), gamma.Bytes())
+			statement := OrStatement(SetMembershipStatement(G, H, V, set), AndStatement(OpeningStatement(G, H, V)))
+			var prover SigmaProver
+			if err := prover.New(statement, OrWitness(0, OrWitness(known, LinearWitness(gamma)))); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := prover.Respond(big.NewInt(1)); !errors.Is(err, ErrMissingState) {
+				t.Fatalf("没有Commit就Respond: err = %v", err)
+			}
+			first, err := prover.Commit()
+			if err != nil {
+				t.Fatal(err)
+			}
+			//无论知道哪个分支，第一条消息只有承诺
+			checkFirstMessage(t, first)
+			c := GenerateRandomScalar()
+			proof, err := prover.Respond(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkFirstMessage(t, first)
+			for i := range first.Children {
+				if len(proof.Children[i].Children) != len(first.Children[i].Children) {
+					t.Fatal("第一条消息与完整证明的结构不同")
+				}
+			}
+			if err := VerifySigma(statement, proof, c); err != nil {
+				t.Fatal(err)
+			}
+			if err := VerifySigma(statement, proof, addInP(c, big.NewInt(1))); !errors.Is(err, ErrSigmaFailed) {
+				t.Fatalf("换了挑战: err = %v", err)
+			}
+			if _, err := prover.Respond(c); !errors.Is(err, ErrMissingState) {
+				t.Fatalf("重复Respond: err = %v", err)
+			}
+		})
+	}
+}
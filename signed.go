@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+)
+
+//有符号值的范围证明：证明-2^(n-1) <= v < 2^(n-1)
+//承诺的是v mod N，负数与N-|v|对应；把承诺平移为V + 2^(n-1)*G后，其中的值v + 2^(n-1)落在[0,2^n)中，
+//再用普通的范围证明即可
+
+//为有符号的v提供承诺，v和gamma都可以是负数，按模N处理
+func CommitSigned(G Point, H Point, v *big.Int, gamma *big.Int) Point {
+	return CommitCT(G, H, modN(v).Bytes(), modN(gamma).Bytes())
+}
+
+//计算平移量2^(n-1)
+func signedOffset(n int64) *big.Int {
+	return big.NewInt(0).Lsh(big.NewInt(1), uint(n-1))
+}
+
+//生成有符号值v的范围证明，V = CommitSigned(G,H,v,gamma)
+func ProveSignedRange(mode RangeProofMode, G Point, H Point, GVector []Point, HVector []Point, v *big.Int, gamma *big.Int, n int64, transcript *Transcript) (RangeProof, error) {
+	if !IsValidRangeWidth(n) {
+		return RangeProof{}, ErrInvalidRangeWidth
+	}
+	offset := signedOffset(n)
+	if v.Cmp(big.NewInt(0).Neg(offset)) < 0 || v.Cmp(offset) >= 0 {
+		return RangeProof{}, ErrValueOutOfRange
+	}
+	transcript.AppendBytes("signed", []byte{1})
+	shifted := big.NewInt(0).Add(v, offset)
+	return ProveRange(mode, G, H, GVector, HVector, shifted, modN(gamma), n, transcript)
+}
+
+//验证V中的有符号值在[-2^(n-1),2^(n-1))中，n是verifier要求的范围
+func VerifySignedRange(G Point, H Point, GVector []Point, HVector []Point, V Point, n int64, proof RangeProof, transcript *Transcript) error {
+	if !IsValidRangeWidth(n) {
+		return ErrInvalidRangeWidth
+	}
+	if !IsOnCurve(V) {
+		return fmt.Errorf("%w: 承诺V", ErrInvalidPoint)
+	}
+	transcript.AppendBytes("signed", []byte{1})
+	shifted := MultiCommit(V, CommitSingle(G, signedOffset(n).Bytes()))
+	return VerifyRange(G, H, GVector, HVector, shifted, n, proof, transcript)
+}
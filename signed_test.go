@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestSignedRange(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(16)
+	tests := []struct {
+		name    string
+		v       int64
+		commit  int64
+		verifyN int64
+		wantErr error
+		invalid bool
+	}{
+		{"最小值", -128, -128, 8, nil, false},
+		{"最大值", 127, 127, 8, nil, false},
+		{"零", 0, 0, 8, nil, false},
+		{"负一", -1, -1, 8, nil, false},
+		{"承诺的值不同", -5, 5, 8, nil, true},
+		{"要求的范围不同", -5, -5, 16, ErrMalformedProof, false},
+	}
+	for _, mode := range rangeProofModes {
+		for _, test := range tests {
+			t.Run(mode.name+"/"+test.name, func(t *testing.T) {
+				gamma := negBig(GenerateRandomScalar())
+				proof, err := ProveSignedRange(mode.mode, G, H, GVector, HVector, big.NewInt(test.v), gamma, 8, newTranscript("signed test"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				V := CommitSigned(G, H, big.NewInt(test.commit), gamma)
+				err = VerifySignedRange(G, H, GVector, HVector, V, test.verifyN, proof, newTranscript("signed test"))
+				switch {
+				case test.invalid:
+					if !IsInvalidProof(err) {
+						t.Fatalf("err = %v，应为证明无效", err)
+					}
+				case !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil):
+					t.Fatalf("err = %v，应为%v", err, test.wantErr)
+				}
+			})
+		}
+	}
+}
+
+func TestSignedRangeOutOfRange(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(8)
+	for _, v := range []int64{128, -129} {
+		_, err := ProveSignedRange(ModeBulletproofs, G, H, GVector, HVector, big.NewInt(v), GenerateRandomScalar(), 8, newTranscript("signed test"))
+		if !errors.Is(err, ErrValueOutOfRange) {
+			t.Fatalf("v = %d: err = %v", v, err)
+		}
+	}
+}
+
+//有符号的证明不能当作普通的范围证明使用
+func TestSignedRangeDomainSeparation(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(8)
+	gamma := GenerateRandomScalar()
+	proof, err := ProveSignedRange(ModeBulletproofs, G, H, GVector, HVector, big.NewInt(3), gamma, 8, newTranscript("signed test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	shifted := CommitCT(G, H, big.NewInt(3+128).Bytes(), gamma.Bytes())
+	if err := VerifyRange(G, H, GVector, HVector, shifted, 8, proof, newTranscript("signed test")); !IsInvalidProof(err) {
+		t.Fatalf("err = %v", err)
+	}
+}
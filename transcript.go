@@ -0,0 +1,71 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+)
+
+//Fiat-Shamir变换使用的transcript
+//prover和verifier按相同的顺序写入公开的消息，由此得到相同的挑战，从而不需要verifier在线发送随机数
+type Transcript struct {
+	state [32]byte
+}
+
+//用协议名初始化transcript，不同协议的挑战互不相同
+func (transcript *Transcript) New(label string) {
+	transcript.state = sha256.Sum256([]byte("RangeProof transcript"))
+	transcript.AppendBytes("protocol", []byte(label))
+}
+
+//写入带标签的任意数据，标签和数据都带长度前缀，避免拼接产生歧义
+func (transcript *Transcript) AppendBytes(label string, data []byte) {
+	h := sha256.New()
+	h.Write(transcript.state[:])
+	writeWithLength(h.Write, []byte(label))
+	writeWithLength(h.Write, data)
+	copy(transcript.state[:], h.Sum(nil))
+}
+
+//写入椭圆曲线上的点
+func (transcript *Transcript) AppendPoint(label string, p Point) {
+	transcript.AppendBytes(label, p.Bytes())
+}
+
+//写入Zp中的数，按固定的32字节编码
+func (transcript *Transcript) AppendScalar(label string, s *big.Int) {
+	var buf [32]byte
+	big.NewInt(0).Mod(s, curve.N).FillBytes(buf[:])
+	transcript.AppendBytes(label, buf[:])
+}
+
+//写入一个整数
+func (transcript *Transcript) AppendInt(label string, i int64) {
+	transcript.AppendBytes(label, Int64ToBytes(i))
+}
+
+//生成Zp中非零的挑战，并把挑战本身写回transcript
+func (transcript *Transcript) ChallengeScalar(label string) *big.Int {
+	var counter uint32
+	for {
+		h := sha256.New()
+		h.Write(transcript.state[:])
+		writeWithLength(h.Write, []byte(label))
+		var c [4]byte
+		binary.BigEndian.PutUint32(c[:], counter)
+		h.Write(c[:])
+		challenge := big.NewInt(0).SetBytes(h.Sum(nil))
+		if challenge.Sign() > 0 && challenge.Cmp(curve.N) < 0 {
+			transcript.AppendScalar(label, challenge)
+			return challenge
+		}
+		counter++
+	}
+}
+
+func writeWithLength(write func([]byte) (int, error), data []byte) {
+	var l [8]byte
+	binary.BigEndian.PutUint64(l[:], uint64(len(data)))
+	write(l[:])
+	write(data)
+}
@@ -2,7 +2,7 @@ package main
 
 import (
 	"encoding/binary"
-	"errors"
+	"github.com/decred/dcrd/dcrec/secp256k1/v3"
 	"math/big"
 	"math/rand"
 	"time"
@@ -36,7 +36,7 @@ func Inner_ProofBig(a []*big.Int,b []*big.Int) *big.Int {
 //a,b是两个int64的数组，要求a,b的长度一致
 func CalHadamardVector(a []byte, b []uint64) ([]uint64,error) {
 	if len(a) != len(b) {
-		return nil,errors.New("两个向量长度不相等")
+		return nil,ErrVectorLength
 	}
 	var c []uint64
 	for i:=0;i<len(a);i++ {
@@ -111,16 +111,18 @@ func CalVectorTimes(a []*big.Int, b int64) []*big.Int {
 }
 
 //生成范围证明中的a_L
-//v是需要承诺的值，n是范围，即v<=2^n-1
+//v是需要承诺的值，n是范围，即v<=2^n-1，v不会被修改
 func GenerateA_L(v *big.Int, n int64) ([]*big.Int,error) {
 
 	var a_L []*big.Int
 	max := big.NewInt(1)
+	//复制一份，避免下面的除法修改调用者传入的v
+	v = big.NewInt(0).Set(v)
 
 	//判断v是否超过了要承诺的范围，即v>2^n-1
 	max.Exp(big.NewInt(2),big.NewInt(n),nil)
 	if v.Cmp(max)>-1 {
-		return nil,errors.New("v超过了要承诺的范围")
+		return nil,ErrValueOutOfRange
 	}
 
 	//计算v的二进制，存入数组中
@@ -165,6 +167,51 @@ func GenerateY(y byte, n int64) []*big.Int {
 	return yVector
 }
 
+//根据底数y(*big.Int)和指数n，生成矢量y^n
+func GenerateYBig(y *big.Int, n int64) []*big.Int {
+	var yVector []*big.Int
+	if n <= 0 {
+		return yVector
+	}
+	yVector = append(yVector, big.NewInt(1))
+	for i := int64(1); i < n; i++ {
+		yVector = append(yVector, mulInP(yVector[i-1], y))
+	}
+	return yVector
+}
+
+//计算向量的倍乘b*a
+//b是*big.Int类型的系数
+func CalVectorTimesBig(a []*big.Int, b *big.Int) []*big.Int {
+	var c []*big.Int
+	for _, value := range a {
+		c = append(c, mulInP(value, b))
+	}
+	return c
+}
+
+//计算两个向量相减a-b
+//a,b均是*big.Int数组
+func CalVectorSub(a []*big.Int, b []*big.Int) []*big.Int {
+	var c []*big.Int
+	for key := range a {
+		c = append(c, subInP(a[key], b[key]))
+	}
+	return c
+}
+
+//生成h' = h^(y^-n)，y是*big.Int类型
+func GenerateH1Big(H []Point, y *big.Int) []Point {
+	yInv := inverseBig(y)
+	var h1 []Point
+	power := big.NewInt(1)
+	for _, value := range H {
+		h1 = append(h1, CommitSingle(value, power.Bytes()))
+		power = mulInP(power, yInv)
+	}
+	return h1
+}
+
 //生成全为z的矢量
 func GenerateZ(z byte, n int64) []byte {
 	var zVector []byte
@@ -190,6 +237,41 @@ func GenerateRandomInt() int {
 	return rand.Int()
 }
 
+//Zp中的数
+type Scalar = secp256k1.ModNScalar
+
+//把Scalar转换为*big.Int，Scalar类型的值可以由此生成承诺和范围证明
+func ScalarToBig(s *Scalar) *big.Int {
+	b := s.Bytes()
+	return big.NewInt(0).SetBytes(b[:])
+}
+
+//范围的最大位数
+//2^n必须远小于群的阶N：n = 256时2^n > N，任何值模N都落在[0,2^n)中，证明不说明任何问题
+//比较等证明还要对两个值的差做范围证明，差模N回绕后也必须落在范围之外
+const maxRangeWidth = 128
+
+//判断范围的位数n是否受支持：1到128之间的任意值
+func IsValidRangeWidth(n int64) bool {
+	return n > 0 && n <= maxRangeWidth
+}
+
+//生成Zp中的随机数，用于盲化因子和随机矢量
+func GenerateRandomScalar() *big.Int {
+	private, _ := secp256k1.GeneratePrivateKey()
+	key := private.Key.Bytes()
+	return big.NewInt(0).SetBytes(key[:])
+}
+
+//生成Zp中的随机矢量
+func GenerateRandomVector(n int64) []*big.Int {
+	var s []*big.Int
+	for i := n; i > 0; i-- {
+		s = append(s, GenerateRandomScalar())
+	}
+	return s
+}
+
 //生成s_L和s_R随机序列
 func GenerateS(n int64)[]*big.Int {
 	var s []*big.Int
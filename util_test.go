@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestIsValidRangeWidth(t *testing.T) {
+	tests := []struct {
+		n     int64
+		valid bool
+	}{
+		{-1, false},
+		{0, false},
+		{1, true},
+		{63, true},
+		{100, true},
+		{128, true},
+		{129, false},
+		{255, false},
+		{256, false},
+	}
+	for _, test := range tests {
+		if IsValidRangeWidth(test.n) != test.valid {
+			t.Errorf("IsValidRangeWidth(%d) = %v", test.n, !test.valid)
+		}
+	}
+}
+
+//18位小数的代币余额超过2^63
+func TestRangeProofWideValues(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(256)
+	balance, _ := big.NewInt(0).SetString("123456789000000000000000000", 10)
+	max128 := big.NewInt(0).Sub(big.NewInt(0).Lsh(big.NewInt(1), 128), big.NewInt(1))
+	tests := []struct {
+		name string
+		mode RangeProofMode
+		v    *big.Int
+		n    int64
+	}{
+		{"100位", ModeBulletproofs, balance, 100},
+		{"128位最大值", ModeBulletproofs, max128, 128},
+		{"Bulletproofs+ 128位", ModeBulletproofsPlus, balance, 128},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := big.NewInt(0).Set(test.v)
+			gamma := GenerateRandomScalar()
+			proof, err := ProveRange(test.mode, G, H, GVector, HVector, v, gamma, test.n, newTranscript("range test"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.Cmp(test.v) != 0 {
+				t.Fatal("ProveRange修改了v")
+			}
+			V := CommitCT(G, H, v.Bytes(), gamma.Bytes())
+			if err := VerifyRange(G, H, GVector, HVector, V, test.n, proof, newTranscript("range test")); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestRangeWidth256Rejected(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(256)
+	v := big.NewInt(0).Sub(curve.N, big.NewInt(1))
+	gamma := GenerateRandomScalar()
+	for _, mode := range rangeProofModes {
+		if _, err := ProveRange(mode.mode, G, H, GVector, HVector, v, gamma, 256, newTranscript("range test")); !errors.Is(err, ErrInvalidRangeWidth) {
+			t.Fatalf("%s: err = %v", mode.name, err)
+		}
+		proof, err := ProveRange(mode.mode, G, H, GVector, HVector, big.NewInt(1), gamma, 128, newTranscript("range test"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		V := CommitCT(G, H, big.NewInt(1).Bytes(), gamma.Bytes())
+		if err := VerifyRange(G, H, GVector, HVector, V, 256, proof, newTranscript("range test")); !errors.Is(err, ErrInvalidRangeWidth) {
+			t.Fatalf("%s: err = %v", mode.name, err)
+		}
+	}
+}
+
+func TestGenerateA_LKeepsInput(t *testing.T) {
+	v := big.NewInt(0xb5)
+	aL, err := GenerateA_L(v, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Int64() != 0xb5 {
+		t.Fatal("GenerateA_L修改了v")
+	}
+	want := []int64{1, 0, 1, 0, 1, 1, 0, 1}
+	for i, bit := range aL {
+		if bit.Int64() != want[i] {
+			t.Fatalf("a_L[%d] = %v", i, bit)
+		}
+	}
+}
@@ -35,21 +35,29 @@ type Verifier struct {
 	proverZKP ProverZKP
 }
 
-func (verifier *Verifier) New(G Point, H Point, GVector []Point, HVector []Point, n int64, curve secp256k1.KoblitzCurve) {
+func (verifier *Verifier) New(G Point, H Point, GVector []Point, HVector []Point, n int64, curve secp256k1.KoblitzCurve) error {
+	if int64(len(GVector)) < n || int64(len(HVector)) < n {
+		return ErrGeneratorsTooShort
+	}
 	verifier.G = G
 	verifier.H = H
-	verifier.GVector = GVector
-	verifier.HVector = HVector
+	verifier.GVector = GVector[:n]
+	verifier.HVector = HVector[:n]
 	verifier.n = n
 	verifier.curve = &curve
 	//verifier.y = 0
 	verifier.y = GenerateRandom()
 
+	return nil
 }
 
-func (verifier *Verifier) GetAS(A Point, S Point) {
+func (verifier *Verifier) GetAS(A Point, S Point) error {
+	if !IsOnCurve(A) || !IsOnCurve(S) {
+		return fmt.Errorf("%w: 承诺A,S", ErrInvalidPoint)
+	}
 	verifier.A = A
 	verifier.S = S
+	return nil
 }
 
 func (verifier *Verifier) GenerateYZ() {
@@ -57,9 +65,13 @@ func (verifier *Verifier) GenerateYZ() {
 	//verifier.z = 0
 }
 
-func (verifier *Verifier) GetT(T1 Point, T2 Point) {
+func (verifier *Verifier) GetT(T1 Point, T2 Point) error {
+	if !IsOnCurve(T1) || !IsOnCurve(T2) {
+		return fmt.Errorf("%w: 承诺T1,T2", ErrInvalidPoint)
+	}
 	verifier.T1 = T1
 	verifier.T2 = T2
+	return nil
 }
 
 func (verifier *Verifier) GenerateX() {
@@ -67,20 +79,44 @@ func (verifier *Verifier) GenerateX() {
 	//verifier.x = 10000000000
 }
 
-func (verifier *Verifier) VerifyZKP() bool {
+//验证零知识证明，证明有效时返回nil
+//证明格式错误时返回ErrMalformedProof，等式不成立时返回对应的错误，可以用IsInvalidProof区分
+func (verifier *Verifier) VerifyZKP() error {
+	if err := verifier.checkProof(); err != nil {
+		return err
+	}
 	if !verifier.verifyTx() {
-		fmt.Println("验证t(x)失败")
-		return false
+		return ErrTxCheckFailed
 	}
 	if !verifier.verifyP() {
-		fmt.Println("验证承诺P失败")
-		return false
+		return ErrCommitPFailed
 	}
 	if !verifier.verifyEqual() {
-		fmt.Println("验证等式相等失败")
-		return false
+		return ErrInnerProductFailed
+	}
+	return nil
+}
+
+//检查prover发送的证明是否完整，标量是否在Zp中，点是否在曲线上
+func (verifier *Verifier) checkProof() error {
+	proof := verifier.proverZKP
+	if proof.taux == nil || proof.mju == nil || proof.tx == nil {
+		return fmt.Errorf("%w: 缺少taux,mju或tx", ErrMalformedProof)
+	}
+	if int64(len(proof.lx)) != verifier.n || int64(len(proof.rx)) != verifier.n {
+		return fmt.Errorf("%w: l(x),r(x)的长度不等于n", ErrMalformedProof)
+	}
+	scalars := append([]*big.Int{proof.taux, proof.mju, proof.tx}, proof.lx...)
+	scalars = append(scalars, proof.rx...)
+	for _, value := range scalars {
+		if value == nil || value.Sign() < 0 || value.Cmp(curve.N) >= 0 {
+			return fmt.Errorf("%w: 标量超出范围", ErrMalformedProof)
+		}
+	}
+	if !IsOnCurve(proof.V) {
+		return fmt.Errorf("%w: 承诺V %v", ErrMalformedProof, ErrInvalidPoint)
 	}
-	return true
+	return nil
 }
 
 //验证t(x)
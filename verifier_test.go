@@ -0,0 +1,152 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+//不经过会话，直接用Prover和Verifier完成一次交互，返回verifier和prover发送的证明
+func runInteractive(t *testing.T, v *big.Int, n int64) (*Verifier, ProverZKP) {
+	G, H, GVector, HVector := testGenerators(n)
+	var prover Prover
+	var verifier Verifier
+	if err := prover.New(G, H, GVector, HVector, v, n, *curve); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifier.New(G, H, GVector, HVector, n, *curve); err != nil {
+		t.Fatal(err)
+	}
+	A, S, err := prover.GetAS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifier.GetAS(A, S); err != nil {
+		t.Fatal(err)
+	}
+	verifier.GenerateYZ()
+	prover.y, prover.z = verifier.y, verifier.z
+	T1, T2, err := prover.GetT()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifier.GetT(T1, T2); err != nil {
+		t.Fatal(err)
+	}
+	verifier.GenerateX()
+	prover.x = verifier.x
+	proverZKP, err := prover.GetProverZKP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &verifier, proverZKP
+}
+
+func TestVerifyZKPErrors(t *testing.T) {
+	one := big.NewInt(1)
+	tests := []struct {
+		name    string
+		tamper  func(proof *ProverZKP)
+		wantErr error
+		invalid bool
+	}{
+		{"诚实的证明", func(proof *ProverZKP) {}, nil, false},
+		{"篡改tx", func(proof *ProverZKP) { proof.tx = addInP(proof.tx, one) }, ErrTxCheckFailed, true},
+		{"篡改taux", func(proof *ProverZKP) { proof.taux = addInP(proof.taux, one) }, ErrTxCheckFailed, true},
+		{"篡改mju", func(proof *ProverZKP) { proof.mju = addInP(proof.mju, one) }, ErrCommitPFailed, true},
+		{"篡改l(x)", func(proof *ProverZKP) { proof.lx[0] = addInP(proof.lx[0], one) }, ErrCommitPFailed, true},
+		{"缺少taux", func(proof *ProverZKP) { proof.taux = nil }, ErrMalformedProof, false},
+		{"l(x)长度不对", func(proof *ProverZKP) { proof.lx = proof.lx[1:] }, ErrMalformedProof, false},
+		{"标量超出Zp", func(proof *ProverZKP) { proof.mju = big.NewInt(0).Set(curve.N) }, ErrMalformedProof, false},
+		{"V不在曲线上", func(proof *ProverZKP) { proof.V = Point{x: big.NewInt(1), y: big.NewInt(1)} }, ErrMalformedProof, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			verifier, proof := runInteractive(t, big.NewInt(100), 8)
+			test.tamper(&proof)
+			verifier.proverZKP = proof
+			err := verifier.VerifyZKP()
+			if !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil) {
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+			if IsInvalidProof(err) != test.invalid {
+				t.Fatalf("IsInvalidProof(%v) = %v", err, !test.invalid)
+			}
+		})
+	}
+}
+
+func TestProverInputErrors(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(8)
+	tests := []struct {
+		name    string
+		v       *big.Int
+		n       int64
+		wantErr error
+	}{
+		{"n为0", big.NewInt(1), 0, ErrInvalidRangeWidth},
+		{"生成元不足", big.NewInt(1), 16, ErrGeneratorsTooShort},
+		{"负数", big.NewInt(-1), 8, ErrValueOutOfRange},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var prover Prover
+			if err := prover.New(G, H, GVector, HVector, test.v, test.n, *curve); !errors.Is(err, test.wantErr) {
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+		})
+	}
+
+	var prover Prover
+	if err := prover.New(G, H, GVector, HVector, big.NewInt(256), 8, *curve); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := prover.GetT(); !errors.Is(err, ErrMissingState) {
+		t.Fatalf("GetAS之前调用GetT: err = %v", err)
+	}
+	if _, _, err := prover.GetAS(); !errors.Is(err, ErrValueOutOfRange) {
+		t.Fatalf("v超出范围: err = %v", err)
+	}
+}
+
+//不经过会话时，Prover回应一次挑战后也不能对另一个x再次作答
+func TestProverAnswersOnce(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(8)
+	var prover Prover
+	if err := prover.New(G, H, GVector, HVector, big.NewInt(5), 8, *curve); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := prover.GetAS(); err != nil {
+		t.Fatal(err)
+	}
+	prover.y, prover.z = 3, 4
+	if _, _, err := prover.GetT(); err != nil {
+		t.Fatal(err)
+	}
+	prover.x = 5
+	if _, err := prover.GetProverZKP(); err != nil {
+		t.Fatal(err)
+	}
+	prover.x = 6
+	if _, err := prover.GetProverZKP(); !errors.Is(err, ErrMissingState) {
+		t.Fatalf("对第二个x作答: err = %v", err)
+	}
+	if _, _, err := prover.GetT(); !errors.Is(err, ErrMissingState) {
+		t.Fatalf("作答后重新生成T1,T2: err = %v", err)
+	}
+}
+
+func TestVerifierRejectsInvalidPoints(t *testing.T) {
+	G, H, GVector, HVector := testGenerators(8)
+	var verifier Verifier
+	if err := verifier.New(G, H, GVector, HVector, 8, *curve); err != nil {
+		t.Fatal(err)
+	}
+	bad := Point{x: big.NewInt(1), y: big.NewInt(1)}
+	if err := verifier.GetAS(bad, G); !errors.Is(err, ErrInvalidPoint) {
+		t.Fatalf("err = %v", err)
+	}
+	if err := verifier.GetT(G, Point{}); !errors.Is(err, ErrInvalidPoint) {
+		t.Fatalf("err = %v", err)
+	}
+}
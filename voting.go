@@ -0,0 +1,224 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+)
+
+//匿名投票：每张选票对每个候选人给出一个对0或1的承诺，用n=1的范围证明说明它是0或1
+//候选人多于一个时，所有承诺之和必须是对1的承诺（独热向量），用打开证明说明Σ承诺打开为1
+//每个承诺V = b*G + gamma*H另附twisted ElGamal句柄D = gamma*PK（见elgamal.go），PK = sk*H是计票人的公钥，ElGamalProof说明两者使用同一个gamma
+//计票时把所有选票的V和D分别同态相加，计票人只解密每个候选人的总数：c*G = ΣV - sk^-1*ΣD
+//计票人用离散对数相等证明说明PK = sk*H且ΣD = sk*(ΣV - c*G)，不需要知道总盲因子，也看不到单张选票的内容
+//没有选票时总数是无穷远点，票数为0，不需要证明
+
+type Election struct {
+	Mode             RangeProofMode
+	G, H             Point
+	GVector, HVector []Point
+	Candidates       int
+	Authority        Point //计票人的公钥PK = sk*H，由Auditor生成
+}
+
+type Ballot struct {
+	Votes       []Point
+	Proofs      []RangeProof
+	Sum         OpeningProof //Candidates > 1时Σ承诺打开为1的证明
+	Handles     []Point      //D_j = gamma_j*PK
+	Consistency []ElGamalProof
+}
+
+type Tally struct {
+	election Election
+	totals   []Point
+	handles  []Point
+	voters   map[string]bool
+}
+
+type TallyResult struct {
+	Counts []*big.Int
+	Proofs []DLEQProof
+}
+
+//选票的transcript覆盖投票人和全部承诺，选票不能被别人冒用
+func ballotTranscript(voterID string, votes []Point) *Transcript {
+	var t Transcript
+	t.New("voting ballot")
+	t.AppendBytes("voter", []byte(voterID))
+	for _, vote := range votes {
+		t.AppendPoint("vote", vote)
+	}
+	return &t
+}
+
+func tallyTranscript(candidate int) *Transcript {
+	var t Transcript
+	t.New("voting tally")
+	t.AppendInt("candidate", int64(candidate))
+	return &t
+}
+
+//投票：只有一个候选人时choice为0或1（反对或赞成），否则choice是所选候选人的下标
+func (election Election) CastBallot(voterID string, choice int) (Ballot, error) {
+	k := election.Candidates
+	bits := make([]*big.Int, k)
+	for j := range bits {
+		bits[j] = big.NewInt(0)
+	}
+	switch {
+	case k == 1 && (choice == 0 || choice == 1):
+		bits[0].SetInt64(int64(choice))
+	case k > 1 && choice >= 0 && choice < k:
+		bits[choice].SetInt64(1)
+	default:
+		return Ballot{}, ErrInvalidChoice
+	}
+
+	var ballot Ballot
+	var gammas []*big.Int
+	sum := big.NewInt(0)
+	for j := 0; j < k; j++ {
+		gamma := GenerateRandomScalar()
+		gammas = append(gammas, gamma)
+		sum = addInP(sum, gamma)
+		ballot.Votes = append(ballot.Votes, CommitCT(election.G, election.H, bits[j].Bytes(), gamma.Bytes()))
+	}
+	transcript := ballotTranscript(voterID, ballot.Votes)
+	for j := 0; j < k; j++ {
+		proof, err := ProveRange(election.Mode, election.G, election.H, election.GVector, election.HVector, bits[j], gammas[j], 1, transcript)
+		if err != nil {
+			return Ballot{}, err
+		}
+		ballot.Proofs = append(ballot.Proofs, proof)
+		ballot.Handles = append(ballot.Handles, ElGamalHandle(election.Authority, gammas[j]))
+		ballot.Consistency = append(ballot.Consistency, ProveElGamal(election.G, election.H, election.Authority, bits[j], gammas[j], transcript))
+	}
+	if k > 1 {
+		ballot.Sum = ProveOpening(election.G, election.H, big.NewInt(1), sum, transcript)
+	}
+	return ballot, nil
+}
+
+//验证选票：每个承诺是0或1且句柄与承诺使用同一个gamma，候选人多于一个时恰好选了一个
+func (election Election) VerifyBallot(voterID string, ballot Ballot) error {
+	k := election.Candidates
+	if len(ballot.Votes) != k || len(ballot.Proofs) != k || len(ballot.Handles) != k || len(ballot.Consistency) != k {
+		return fmt.Errorf("%w: 选票的长度与候选人数不符", ErrMalformedProof)
+	}
+	for _, vote := range ballot.Votes {
+		if !IsOnCurve(vote) {
+			return fmt.Errorf("%w: %v", ErrMalformedProof, ErrInvalidPoint)
+		}
+	}
+	transcript := ballotTranscript(voterID, ballot.Votes)
+	for j := 0; j < k; j++ {
+		if err := VerifyRange(election.G, election.H, election.GVector, election.HVector, ballot.Votes[j], 1, ballot.Proofs[j], transcript); err != nil {
+			return err
+		}
+		if err := VerifyElGamal(election.G, election.H, election.Authority, ballot.Votes[j], ballot.Handles[j], ballot.Consistency[j], transcript); err != nil {
+			return err
+		}
+	}
+	if k > 1 {
+		sum := ballot.Votes[0]
+		for _, vote := range ballot.Votes[1:] {
+			sum = MultiCommit(sum, vote)
+		}
+		return VerifyOpening(election.G, election.H, sum, big.NewInt(1), ballot.Sum, transcript)
+	}
+	return nil
+}
+
+func (tally *Tally) New(election Election) {
+	tally.election = election
+	tally.totals = make([]Point, election.Candidates)
+	tally.handles = make([]Point, election.Candidates)
+	for j := range tally.totals {
+		tally.totals[j] = Point{x: big.NewInt(0), y: big.NewInt(0)}
+		tally.handles[j] = Point{x: big.NewInt(0), y: big.NewInt(0)}
+	}
+	tally.voters = make(map[string]bool)
+}
+
+//验证选票并计入总数，每个投票人只能投一次
+func (tally *Tally) Add(voterID string, ballot Ballot) error {
+	if tally.voters[voterID] {
+		return fmt.Errorf("%w: %q", ErrDuplicateVoter, voterID)
+	}
+	if err := tally.election.VerifyBallot(voterID, ballot); err != nil {
+		return err
+	}
+	for j := range ballot.Votes {
+		tally.totals[j] = MultiCommit(tally.totals[j], ballot.Votes[j])
+		tally.handles[j] = MultiCommit(tally.handles[j], ballot.Handles[j])
+	}
+	tally.voters[voterID] = true
+	return nil
+}
+
+//每个候选人的总承诺，任何人都可以由公开的选票重新计算
+func (tally Tally) Totals() []Point {
+	return append([]Point{}, tally.totals...)
+}
+
+//每个候选人的总句柄，任何人都可以由公开的选票重新计算
+func (tally Tally) Handles() []Point {
+	return append([]Point{}, tally.handles...)
+}
+
+func isIdentity(p Point) bool {
+	return IsEqual(p, Point{x: big.NewInt(0), y: big.NewInt(0)})
+}
+
+//ΣV - c*G，计票正确时等于总盲因子乘以H
+func tallyBase(G Point, total Point, count *big.Int) Point {
+	return MultiCommit(total, CommitSingle(G, negBig(modN(count)).Bytes()))
+}
+
+//计票人只解密每个候选人的总数，并证明PK = sk*H，ΣD = sk*(ΣV - c*G)
+//auditor必须是生成election.Authority的计票人，能解密的位数要覆盖投票人数
+func (tally Tally) Open(auditor Auditor) (TallyResult, error) {
+	election := tally.election
+	if !IsEqual(auditor.PK, election.Authority) {
+		return TallyResult{}, fmt.Errorf("%w: 计票人的公钥与选举不符", ErrDecryptFailed)
+	}
+	var result TallyResult
+	for j := range tally.totals {
+		if isIdentity(tally.totals[j]) && isIdentity(tally.handles[j]) {
+			result.Counts = append(result.Counts, big.NewInt(0))
+			result.Proofs = append(result.Proofs, DLEQProof{})
+			continue
+		}
+		count, err := auditor.Decrypt(tally.totals[j], tally.handles[j])
+		if err != nil {
+			return TallyResult{}, fmt.Errorf("候选人%d: %w", j, err)
+		}
+		result.Counts = append(result.Counts, count)
+		result.Proofs = append(result.Proofs, ProveDLEQ(election.H, tallyBase(election.G, tally.totals[j], count), auditor.sk, tallyTranscript(j)))
+	}
+	return result, nil
+}
+
+//验证公开的票数与总承诺、总句柄一致；总承诺和总句柄都是无穷远点时票数必须为0
+func (election Election) VerifyTally(totals []Point, handles []Point, result TallyResult) error {
+	k := election.Candidates
+	if len(totals) != k || len(handles) != k || len(result.Counts) != k || len(result.Proofs) != k {
+		return fmt.Errorf("%w: 计票结果的长度与候选人数不符", ErrMalformedProof)
+	}
+	for j := range totals {
+		count := result.Counts[j]
+		if count == nil || count.Sign() < 0 || count.Cmp(curve.N) >= 0 {
+			return fmt.Errorf("候选人%d: %w", j, ErrValueOutOfRange)
+		}
+		if isIdentity(totals[j]) && isIdentity(handles[j]) {
+			if count.Sign() != 0 {
+				return fmt.Errorf("候选人%d: %w: 没有选票但票数不为0", j, ErrDLEQFailed)
+			}
+			continue
+		}
+		if err := VerifyDLEQ(election.H, tallyBase(election.G, totals[j], count), election.Authority, handles[j], result.Proofs[j], tallyTranscript(j)); err != nil {
+			return fmt.Errorf("候选人%d: %w", j, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+//测试用的选举和计票人，计票人能解密16位以内的票数
+func testElection(t *testing.T, candidates int) (Election, Auditor) {
+	G, H, GVector, HVector := testGenerators(1)
+	var auditor Auditor
+	if err := auditor.New(G, H, 16); err != nil {
+		t.Fatal(err)
+	}
+	return Election{Mode: ModeBulletproofs, G: G, H: H, GVector: GVector, HVector: HVector, Candidates: candidates, Authority: auditor.PK}, auditor
+}
+
+func TestVoting(t *testing.T) {
+	election, auditor := testElection(t, 3)
+	var tally Tally
+	tally.New(election)
+	choices := map[string]int{"alice": 0, "bob": 2, "carol": 2, "dave": 1, "erin": 2}
+	for voter, choice := range choices {
+		ballot, err := election.CastBallot(voter, choice)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tally.Add(voter, ballot); err != nil {
+			t.Fatalf("%s: %v", voter, err)
+		}
+	}
+	result, err := tally.Open(auditor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j, want := range []int64{1, 1, 3} {
+		if result.Counts[j].Int64() != want {
+			t.Fatalf("候选人%d得到%v票，应为%d票", j, result.Counts[j], want)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		tamper  func(result *TallyResult)
+		wantErr error
+	}{
+		{"诚实的计票", nil, nil},
+		{"票数多一票", func(result *TallyResult) { result.Counts[2] = big.NewInt(4) }, ErrDLEQFailed},
+		{"交换两个候选人的证明", func(result *TallyResult) { result.Proofs[0], result.Proofs[1] = result.Proofs[1], result.Proofs[0] }, ErrDLEQFailed},
+		{"票数为负", func(result *TallyResult) { result.Counts[0] = big.NewInt(-1) }, ErrValueOutOfRange},
+		{"少一个候选人", func(result *TallyResult) { result.Counts = result.Counts[:2] }, ErrMalformedProof},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tampered := TallyResult{Counts: append([]*big.Int{}, result.Counts...), Proofs: append([]DLEQProof{}, result.Proofs...)}
+			if test.tamper != nil {
+				test.tamper(&tampered)
+			}
+			err := election.VerifyTally(tally.Totals(), tally.Handles(), tampered)
+			if !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil) {
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+		})
+	}
+
+	//别的计票人不能计票
+	_, other := testElection(t, 3)
+	if _, err := tally.Open(other); !errors.Is(err, ErrDecryptFailed) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestEmptyTally(t *testing.T) {
+	election, auditor := testElection(t, 2)
+	var tally Tally
+	tally.New(election)
+	result, err := tally.Open(auditor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j, count := range result.Counts {
+		if count.Sign() != 0 {
+			t.Fatalf("候选人%d得到%v票", j, count)
+		}
+	}
+	if err := election.VerifyTally(tally.Totals(), tally.Handles(), result); err != nil {
+		t.Fatal(err)
+	}
+	result.Counts[1] = big.NewInt(1)
+	if err := election.VerifyTally(tally.Totals(), tally.Handles(), result); !errors.Is(err, ErrDLEQFailed) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestBallot(t *testing.T) {
+	election, _ := testElection(t, 2)
+	ballot, err := election.CastBallot("alice", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := election.CastBallot("bob", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		voter   string
+		tamper  func(ballot *Ballot)
+		wantErr error
+		reject  bool
+	}{
+		{"诚实的选票", "alice", nil, nil, false},
+		{"冒用别人的选票", "bob", nil, nil, true},
+		{"替换句柄", "alice", func(ballot *Ballot) { ballot.Handles[0] = other.Handles[0] }, ErrElGamalFailed, false},
+		{"替换一个承诺", "alice", func(ballot *Ballot) { ballot.Votes[0] = other.Votes[0] }, nil, true},
+		{"缺少句柄", "alice", func(ballot *Ballot) { ballot.Handles = ballot.Handles[:1] }, ErrMalformedProof, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tampered := ballot
+			tampered.Votes = append([]Point{}, ballot.Votes...)
+			tampered.Handles = append([]Point{}, ballot.Handles...)
+			if test.tamper != nil {
+				test.tamper(&tampered)
+			}
+			err := election.VerifyBallot(test.voter, tampered)
+			switch {
+			case test.reject:
+				if !IsInvalidProof(err) {
+					t.Fatalf("err = %v，应为证明无效", err)
+				}
+			case !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil):
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+		})
+	}
+
+	if _, err := election.CastBallot("carol", 2); !errors.Is(err, ErrInvalidChoice) {
+		t.Fatalf("err = %v", err)
+	}
+	var tally Tally
+	tally.New(election)
+	if err := tally.Add("alice", ballot); err != nil {
+		t.Fatal(err)
+	}
+	if err := tally.Add("alice", ballot); !errors.Is(err, ErrDuplicateVoter) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestReferendum(t *testing.T) {
+	election, auditor := testElection(t, 1)
+	var tally Tally
+	tally.New(election)
+	for i, choice := range []int{1, 0, 1, 1} {
+		voter := string(rune('a' + i))
+		ballot, err := election.CastBallot(voter, choice)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tally.Add(voter, ballot); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result, err := tally.Open(auditor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Counts[0].Int64() != 3 {
+		t.Fatalf("赞成票为%v", result.Counts[0])
+	}
+	if err := election.VerifyTally(tally.Totals(), tally.Handles(), result); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := election.CastBallot("e", 2); !errors.Is(err, ErrInvalidChoice) {
+		t.Fatalf("err = %v", err)
+	}
+}
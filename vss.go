@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+)
+
+//Pedersen可验证秘密分享
+//庄家选择f(x) = s + a_1*x + ... + a_(k-1)*x^(k-1)和g(x) = t + b_1*x + ...，公开C_i = Commit(G,H,a_i,b_i)
+//第j个持有者收到(f(j),g(j))，检查f(j)*G + g(j)*H = Σ j^i*C_i
+//份额不对时持有者公开投诉，庄家必须公开该份额；公开的份额仍然不对，或庄家不回应，庄家就被判定作弊
+//任意k个有效份额可以用拉格朗日插值恢复s；C_i是完美隐藏的，不泄露s的任何信息
+
+type VSSShare struct {
+	Index int64
+	S, T  *big.Int
+}
+
+type Dealer struct {
+	G, H        Point
+	Commitments []Point
+	f, g        []*big.Int
+	n           int64
+}
+
+type Shareholder struct {
+	G, H        Point
+	Index       int64
+	commitments []Point
+	share       *VSSShare
+}
+
+//持有者对庄家的投诉
+type Complaint struct {
+	Accuser int64
+}
+
+//庄家分享secret，任意threshold个份额可以恢复，共n个持有者，下标为1..n
+func (dealer *Dealer) New(G Point, H Point, secret *big.Int, threshold int, n int64) error {
+	if threshold < 1 || int64(threshold) > n {
+		return ErrInvalidThreshold
+	}
+	dealer.G, dealer.H, dealer.n = G, H, n
+	dealer.f = []*big.Int{modN(secret)}
+	dealer.g = []*big.Int{GenerateRandomScalar()}
+	for i := 1; i < threshold; i++ {
+		dealer.f = append(dealer.f, GenerateRandomScalar())
+		dealer.g = append(dealer.g, GenerateRandomScalar())
+	}
+	dealer.Commitments = nil
+	for i := range dealer.f {
+		dealer.Commitments = append(dealer.Commitments, CommitCT(G, H, dealer.f[i].Bytes(), dealer.g[i].Bytes()))
+	}
+	return nil
+}
+
+//计算多项式在x处的值
+func evalPolynomial(coefficients []*big.Int, x int64) *big.Int {
+	result := big.NewInt(0)
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		result = addInP(mulInP(result, big.NewInt(x)), coefficients[i])
+	}
+	return result
+}
+
+//第index个持有者的份额
+func (dealer Dealer) Share(index int64) (VSSShare, error) {
+	if index < 1 || index > dealer.n {
+		return VSSShare{}, fmt.Errorf("%w: 持有者下标必须在1..%d中", ErrMalformedProof, dealer.n)
+	}
+	return VSSShare{Index: index, S: evalPolynomial(dealer.f, index), T: evalPolynomial(dealer.g, index)}, nil
+}
+
+//回应投诉：公开投诉者的份额
+func (dealer Dealer) Respond(complaint Complaint) (VSSShare, error) {
+	return dealer.Share(complaint.Accuser)
+}
+
+//检查份额与公开的承诺一致：S*G + T*H = Σ index^i*C_i
+func VerifyShare(G Point, H Point, commitments []Point, share VSSShare) error {
+	if len(commitments) == 0 {
+		return fmt.Errorf("%w: 没有系数承诺", ErrMalformedProof)
+	}
+	for _, C := range commitments {
+		if !IsOnCurve(C) {
+			return fmt.Errorf("%w: 系数承诺", ErrInvalidPoint)
+		}
+	}
+	if share.Index < 1 || share.S == nil || share.T == nil {
+		return fmt.Errorf("%w: 份额格式错误", ErrMalformedProof)
+	}
+	expected := commitments[len(commitments)-1]
+	x := big.NewInt(share.Index).Bytes()
+	for i := len(commitments) - 2; i >= 0; i-- {
+		expected = MultiCommit(CommitSingle(expected, x), commitments[i])
+	}
+	if !IsEqual(Commit(G, H, modN(share.S).Bytes(), modN(share.T).Bytes()), expected) {
+		return ErrShareInvalid
+	}
+	return nil
+}
+
+func (holder *Shareholder) New(G Point, H Point, index int64, commitments []Point) {
+	holder.G, holder.H, holder.Index = G, H, index
+	holder.commitments = append([]Point{}, commitments...)
+	holder.share = nil
+}
+
+//接收并检查份额，份额无效时返回投诉
+func (holder *Shareholder) Receive(share VSSShare) (*Complaint, error) {
+	if share.Index != holder.Index {
+		return &Complaint{Accuser: holder.Index}, fmt.Errorf("%w: 份额的下标不是%d", ErrShareInvalid, holder.Index)
+	}
+	if err := VerifyShare(holder.G, holder.H, holder.commitments, share); err != nil {
+		return &Complaint{Accuser: holder.Index}, err
+	}
+	holder.share = &share
+	return nil, nil
+}
+
+//已接收的有效份额
+func (holder Shareholder) Share() (VSSShare, error) {
+	if holder.share == nil {
+		return VSSShare{}, ErrMissingState
+	}
+	return *holder.share, nil
+}
+
+//所有人检查庄家对投诉的回应：公开的份额有效时投诉者采用它，否则庄家作弊
+func ResolveComplaint(G Point, H Point, commitments []Point, complaint Complaint, response VSSShare) error {
+	if response.Index != complaint.Accuser {
+		return fmt.Errorf("%w: 回应的不是投诉者的份额", ErrDealerFaulty)
+	}
+	if err := VerifyShare(G, H, commitments, response); err != nil {
+		return fmt.Errorf("%w: %v", ErrDealerFaulty, err)
+	}
+	return nil
+}
+
+//用有效的份额恢复秘密s和t，无效的份额被丢弃，有效份额不足threshold个时出错
+func Reconstruct(G Point, H Point, commitments []Point, shares []VSSShare) (*big.Int, *big.Int, error) {
+	threshold := len(commitments)
+	var valid []VSSShare
+	seen := make(map[int64]bool)
+	for _, share := range shares {
+		if seen[share.Index] || VerifyShare(G, H, commitments, share) != nil {
+			continue
+		}
+		seen[share.Index] = true
+		valid = append(valid, share)
+		if len(valid) == threshold {
+			break
+		}
+	}
+	if threshold == 0 || len(valid) < threshold {
+		return nil, nil, ErrNotEnoughShares
+	}
+
+	//s = Σ λ_j*f(j)，λ_j = Π_(m≠j) m/(m-j)
+	s, t := big.NewInt(0), big.NewInt(0)
+	for j, share := range valid {
+		lambda := big.NewInt(1)
+		for m, other := range valid {
+			if m == j {
+				continue
+			}
+			xm := big.NewInt(other.Index)
+			lambda = mulInP(lambda, mulInP(xm, inverseBig(subInP(xm, big.NewInt(share.Index)))))
+		}
+		s = addInP(s, mulInP(lambda, modN(share.S)))
+		t = addInP(t, mulInP(lambda, modN(share.T)))
+	}
+	return s, t, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestVSSShare(t *testing.T) {
+	G, H := GeneratePoint(), GeneratePoint()
+	var dealer Dealer
+	if err := dealer.New(G, H, big.NewInt(42), 3, 5); err != nil {
+		t.Fatal(err)
+	}
+	share, err := dealer.Share(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		tamper  func(share *VSSShare)
+		wantErr error
+	}{
+		{"诚实的份额", nil, nil},
+		{"篡改S", func(share *VSSShare) { share.S = addInP(share.S, big.NewInt(1)) }, ErrShareInvalid},
+		{"篡改T", func(share *VSSShare) { share.T = addInP(share.T, big.NewInt(1)) }, ErrShareInvalid},
+		{"别人的下标", func(share *VSSShare) { share.Index = 3 }, ErrShareInvalid},
+		{"下标为0", func(share *VSSShare) { share.Index = 0 }, ErrMalformedProof},
+		{"缺少T", func(share *VSSShare) { share.T = nil }, ErrMalformedProof},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tampered := share
+			if test.tamper != nil {
+				test.tamper(&tampered)
+			}
+			err := VerifyShare(G, H, dealer.Commitments, tampered)
+			if !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil) {
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+		})
+	}
+	if _, err := dealer.Share(6); !errors.Is(err, ErrMalformedProof) {
+		t.Fatalf("err = %v", err)
+	}
+	if err := dealer.New(G, H, big.NewInt(42), 6, 5); !errors.Is(err, ErrInvalidThreshold) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestVSSComplaint(t *testing.T) {
+	G, H := GeneratePoint(), GeneratePoint()
+	var dealer Dealer
+	if err := dealer.New(G, H, big.NewInt(7), 2, 3); err != nil {
+		t.Fatal(err)
+	}
+	var holder Shareholder
+	holder.New(G, H, 1, dealer.Commitments)
+	if _, err := holder.Share(); !errors.Is(err, ErrMissingState) {
+		t.Fatalf("err = %v", err)
+	}
+
+	//庄家发出错误的份额，持有者投诉，庄家公开正确的份额后投诉得到解决
+	share, _ := dealer.Share(1)
+	bad := share
+	bad.S = addInP(bad.S, big.NewInt(1))
+	complaint, err := holder.Receive(bad)
+	if !errors.Is(err, ErrShareInvalid) || complaint == nil || complaint.Accuser != 1 {
+		t.Fatalf("complaint = %v, err = %v", complaint, err)
+	}
+	response, _ := dealer.Respond(*complaint)
+	if err := ResolveComplaint(G, H, dealer.Commitments, *complaint, response); err != nil {
+		t.Fatal(err)
+	}
+	if err := ResolveComplaint(G, H, dealer.Commitments, *complaint, bad); !errors.Is(err, ErrDealerFaulty) {
+		t.Fatalf("公开的份额仍然错误: err = %v", err)
+	}
+	other, _ := dealer.Share(2)
+	if err := ResolveComplaint(G, H, dealer.Commitments, *complaint, other); !errors.Is(err, ErrDealerFaulty) {
+		t.Fatalf("公开了别人的份额: err = %v", err)
+	}
+	if complaint, err := holder.Receive(response); err != nil || complaint != nil {
+		t.Fatalf("complaint = %v, err = %v", complaint, err)
+	}
+	if got, err := holder.Share(); err != nil || got.S.Cmp(share.S) != 0 {
+		t.Fatalf("share = %v, err = %v", got, err)
+	}
+	if complaint, err := holder.Receive(other); !errors.Is(err, ErrShareInvalid) || complaint == nil {
+		t.Fatalf("收到别人的份额: complaint = %v, err = %v", complaint, err)
+	}
+}
+
+func TestVSSReconstruct(t *testing.T) {
+	G, H := GeneratePoint(), GeneratePoint()
+	secret := big.NewInt(123456789)
+	var dealer Dealer
+	if err := dealer.New(G, H, secret, 3, 5); err != nil {
+		t.Fatal(err)
+	}
+	var shares []VSSShare
+	for i := int64(1); i <= 5; i++ {
+		share, _ := dealer.Share(i)
+		shares = append(shares, share)
+	}
+	bad := shares[0]
+	bad.S = addInP(bad.S, big.NewInt(1))
+
+	tests := []struct {
+		name    string
+		shares  []VSSShare
+		wantErr error
+	}{
+		{"前三个份额", shares[:3], nil},
+		{"后三个份额", shares[2:], nil},
+		{"全部份额", shares, nil},
+		{"丢弃无效的份额", []VSSShare{bad, shares[1], shares[3], shares[4]}, nil},
+		{"重复的份额不计数", []VSSShare{shares[1], shares[1], shares[3]}, ErrNotEnoughShares},
+		{"有效份额不足", []VSSShare{bad, shares[1], shares[3]}, ErrNotEnoughShares},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, _, err := Reconstruct(G, H, dealer.Commitments, test.shares)
+			if !errors.Is(err, test.wantErr) || (err == nil) != (test.wantErr == nil) {
+				t.Fatalf("err = %v，应为%v", err, test.wantErr)
+			}
+			if err == nil && s.Cmp(secret) != 0 {
+				t.Fatalf("恢复的秘密为%v", s)
+			}
+		})
+	}
+}